@@ -0,0 +1,32 @@
+package putingh
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WithRepoAllowlist rejects with ErrForbidden every uri and method call whose target fn does not allow,
+// before any request is sent. The repo of a gist is its gist id, "*" when the gists of owner are searched,
+// and the owner is empty for a gist known only by its id.
+func WithRepoAllowlist(fn func(scheme, owner, repo string) bool) Option {
+	return func(p *PutInGH) {
+		p.repoAllowlist = fn
+	}
+}
+
+func (s *PutInGH) checkAllowed(scheme, owner, repo string) error {
+	if s.repoAllowlist == nil || s.repoAllowlist(scheme, owner, repo) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s://%s/%s", ErrForbidden, scheme, owner, repo)
+}
+
+// checkURIAllowed checks the target of u, http and https are checked once mapped to their gist uri.
+func (s *PutInGH) checkURIAllowed(u *url.URL) error {
+	if s.repoAllowlist == nil || u.Scheme == "http" || u.Scheme == "https" {
+		return nil
+	}
+	repo := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)[0]
+	return s.checkAllowed(u.Scheme, u.Host, repo)
+}
@@ -0,0 +1,171 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestRepoAllowlistDeniesWithoutRequests(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	var requests atomic.Int32
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(rw, r)
+	})
+	putter := newPutter(t, srv, putingh.WithRepoAllowlist(func(scheme, owner, repo string) bool {
+		return false
+	}))
+	ctx := context.Background()
+	files := func() map[string]io.Reader {
+		return map[string]io.Reader{"name.txt": strings.NewReader("content")}
+	}
+
+	calls := map[string]func() error{
+		"GetFrom": func() error {
+			_, err := putter.GetFrom(ctx, "git://"+owner+"/repo/main/name.txt")
+			return err
+		},
+		"PutIn": func() error {
+			_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("content"))
+			return err
+		},
+		"PutBytes": func() error {
+			_, err := putter.PutBytes(ctx, "asset://"+owner+"/repo/v1/name.txt", []byte("content"))
+			return err
+		},
+		"Stat": func() error {
+			_, err := putter.Stat(ctx, "asset://"+owner+"/repo/v1/name.txt")
+			return err
+		},
+		"GetFromArchive": func() error {
+			_, err := putter.GetFromArchive(ctx, owner, "repo", "", "")
+			return err
+		},
+		"PutInAssetFromURL": func() error {
+			_, err := putter.PutInAssetFromURL(ctx, owner, "repo", "v1", "name.txt", srv.URL)
+			return err
+		},
+		"ListReleaseAssets": func() error {
+			_, err := putter.ListReleaseAssets(ctx, owner, "repo", "v1")
+			return err
+		},
+		"Batch": func() error {
+			b := putter.Batch(owner, "repo", "main")
+			b.Add("name.txt", strings.NewReader("content"))
+			_, err := b.Commit(ctx)
+			return err
+		},
+		"PutInGitDir": func() error {
+			_, err := putter.PutInGitDir(ctx, owner, "repo", "main", "", t.TempDir())
+			return err
+		},
+		"GetFileFromGit": func() error {
+			_, err := putter.GetFileFromGit(ctx, owner, "repo", "main", "name.txt")
+			return err
+		},
+		"GetFileFromReleasesAsset": func() error {
+			_, err := putter.GetFileFromReleasesAsset(ctx, owner, "repo", "v1", "name.txt")
+			return err
+		},
+		"FindGists": func() error {
+			_, err := putter.FindGists(ctx, owner, nil)
+			return err
+		},
+		"GetGistComments": func() error {
+			_, err := putter.GetGistComments(ctx, owner, "id")
+			return err
+		},
+		"AddGistComment": func() error {
+			return putter.AddGistComment(ctx, "id", "body")
+		},
+		"PutInGistFiles": func() error {
+			_, err := putter.PutInGistFiles(ctx, owner, "id", files())
+			return err
+		},
+		"GetFromGistAll": func() error {
+			_, err := putter.GetFromGistAll(ctx, owner, "id")
+			return err
+		},
+		"OpenGistKV": func() error {
+			_, err := putter.OpenGistKV(ctx, owner, "id")
+			return err
+		},
+		"PutInGitCommit": func() error {
+			_, _, err := putter.PutInGitCommit(ctx, owner, "repo", "main", "name.txt", strings.NewReader("content"))
+			return err
+		},
+		"PutInGitFiles": func() error {
+			_, err := putter.PutInGitFiles(ctx, owner, "repo", "main", files())
+			return err
+		},
+		"DeleteGlob": func() error {
+			_, err := putter.DeleteGlob(ctx, owner, "repo", "main", "*")
+			return err
+		},
+		"GetFromGitWithInfo": func() error {
+			_, _, err := putter.GetFromGitWithInfo(ctx, owner, "repo", "main", "name.txt")
+			return err
+		},
+		"GitFileVersions": func() error {
+			return putter.GitFileVersions(ctx, owner, "repo", "main", "name.txt", func(string, io.Reader) bool { return true })
+		},
+		"GetFromGist": func() error {
+			_, err := putter.GetFromGist(ctx, owner, "id", "name.txt")
+			return err
+		},
+		"GetFromGistLines": func() error {
+			_, err := putter.GetFromGistLines(ctx, owner, "id", "name.txt", 1, 2)
+			return err
+		},
+		"GetFromReleasesAsset": func() error {
+			_, err := putter.GetFromReleasesAsset(ctx, owner, "repo", "v1", "name.txt")
+			return err
+		},
+		"GetOrCreateRelease": func() error {
+			_, _, err := putter.GetOrCreateRelease(ctx, owner, "repo", "v1")
+			return err
+		},
+		"GetFromGit": func() error {
+			_, err := putter.GetFromGit(ctx, owner, "repo", "main", "name.txt")
+			return err
+		},
+		"PutInGitIfMatch": func() error {
+			_, err := putter.PutInGitIfMatch(ctx, owner, "repo", "main", "name.txt", "", strings.NewReader("content"))
+			return err
+		},
+		"ReconcileGit": func() error {
+			_, err := putter.ReconcileGit(ctx, owner, "repo", "main", files())
+			return err
+		},
+		"ReconcileGitWithPrefix": func() error {
+			_, err := putter.ReconcileGitWithPrefix(ctx, owner, "repo", "main", "docs", files())
+			return err
+		},
+		"GetFromReleaseNotes": func() error {
+			_, err := putter.GetFromReleaseNotes(ctx, owner, "repo", "v1")
+			return err
+		},
+		"SwapGit": func() error {
+			_, err := putter.SwapGit(ctx, owner, "repo", "main", "a.txt", "b.txt")
+			return err
+		},
+	}
+	for name, call := range calls {
+		err := call()
+		if !errors.Is(err, putingh.ErrForbidden) {
+			t.Errorf("%s: got %v, want ErrForbidden", name, err)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("%d requests were sent for denied targets", n)
+	}
+}
@@ -0,0 +1,52 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// GetFromArchive streams the tarball or zipball of the repository at ref,
+// an empty ref is the default branch and an empty format is tarball.
+func (s *PutInGH) GetFromArchive(ctx context.Context, owner, repo, ref, format string) (io.Reader, error) {
+	if err := s.checkAllowed("archive", owner, repo); err != nil {
+		return nil, err
+	}
+	var archiveFormat ghv3.ArchiveFormat
+	switch format {
+	case "", string(ghv3.Tarball):
+		archiveFormat = ghv3.Tarball
+	case string(ghv3.Zipball):
+		archiveFormat = ghv3.Zipball
+	default:
+		return nil, fmt.Errorf("archive format %q not support", format)
+	}
+	if s.isDefaultBranch(ref) {
+		ref = s.defaultBranch
+	}
+	link, response, err := s.cliv3.Repositories.GetArchiveLink(ctx, owner, repo, archiveFormat, &ghv3.RepositoryContentGetOptions{
+		Ref: ref,
+	}, 1)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s/%s@%s", ErrNotFound, owner, repo, ref)
+		}
+		return nil, err
+	}
+	// the download follows the rules for raw content, the token only goes to GitHub hosts
+	resp, err := s.httpGet(ctx, link.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, link)
+		}
+		return nil, fmt.Errorf("download %s: %s", link, resp.Status)
+	}
+	return newReaderWithAutoCloser(resp.Body), nil
+}
@@ -0,0 +1,152 @@
+package putingh_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGetFromArchive(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"README.md":  "readme",
+		"docs/a.txt": "a",
+	})
+	want := map[string]string{
+		"repo-main/README.md":  "readme",
+		"repo-main/docs/a.txt": "a",
+	}
+	for format, extract := range map[string]func(t *testing.T, data []byte) map[string]string{
+		"tarball": untar,
+		"zipball": unzip,
+	} {
+		t.Run(format, func(t *testing.T) {
+			r, err := putter.GetFrom(context.Background(), "archive://"+owner+"/repo/main?format="+format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := extract(t, data)
+			if len(got) != len(want) {
+				t.Fatalf("got files %v, want %v", keys(got), keys(want))
+			}
+			for name, content := range want {
+				if got[name] != content {
+					t.Fatalf("%s: got %q, want %q", name, got[name], content)
+				}
+			}
+		})
+	}
+}
+
+func TestGetFromArchiveTokenStaysOnGitHub(t *testing.T) {
+	// codeload, the fake GitHub listens on 127.0.0.1
+	var leaked atomic.Value
+	leaked.Store("")
+	codeload := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		leaked.Store(r.Header.Get("Authorization"))
+		rw.Write([]byte("archive"))
+	}))
+	t.Cleanup(codeload.Close)
+	codeloadURL := strings.Replace(codeload.URL, "127.0.0.1", "localhost", 1)
+
+	srv, putter := putinghtest.NewServer(t)
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/repos/"+owner+"/repo/tarball/main" {
+			http.Redirect(rw, r, codeloadURL+"/"+owner+"/repo/legacy.tar.gz/main", http.StatusFound)
+			return
+		}
+		handler.ServeHTTP(rw, r)
+	})
+
+	r, err := putter.GetFrom(context.Background(), "archive://"+owner+"/repo/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "archive" {
+		t.Fatalf("got %q, want %q", got, "archive")
+	}
+	if auth := leaked.Load().(string); auth != "" {
+		t.Fatalf("sent the token %q to %s", auth, codeloadURL)
+	}
+}
+
+func untar(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(content)
+	}
+}
+
+func unzip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func keys(m map[string]string) []string {
+	list := make([]string, 0, len(m))
+	for key := range m {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
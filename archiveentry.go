@@ -0,0 +1,127 @@
+package putingh
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// GetFromArchiveEntry streams the single file entry out of the .tar.gz, .tgz, .tar or .zip behind uri,
+// the format is told by the extension of uri.
+// A zip is spooled to a temp file to be read, a tar is read as it downloads.
+func (s *PutInGH) GetFromArchiveEntry(ctx context.Context, uri, entry string) (io.Reader, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(path.Base(u.Path))
+	var open func(io.Reader, string) (io.Reader, error)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		open = openTarGzEntry
+	case strings.HasSuffix(name, ".tar"):
+		open = openTarEntry
+	case strings.HasSuffix(name, ".zip"):
+		open = s.openZipEntry
+	default:
+		return nil, fmt.Errorf("archive %q not support", path.Base(u.Path))
+	}
+
+	r, err := s.GetFrom(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	entryReader, err := open(r, cleanArchiveEntry(entry))
+	if err != nil {
+		closeReader(r)
+		if err == ErrNotFound {
+			return nil, fmt.Errorf("%w: %s in %s", ErrNotFound, entry, uri)
+		}
+		return nil, err
+	}
+	return entryReader, nil
+}
+
+func cleanArchiveEntry(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func openTarGzEntry(r io.Reader, entry string) (io.Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return openTarEntry(&readCloser{
+		Reader:  gz,
+		closers: []io.Closer{gz, closerFunc(func() error { return closeReader(r) })},
+	}, entry)
+}
+
+// openTarEntry returns the content of entry, r is closed once the entry has been read.
+func openTarEntry(r io.Reader, entry string) (io.Reader, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		if hdr.FileInfo().Mode().IsRegular() && cleanArchiveEntry(hdr.Name) == entry {
+			return newReaderWithAutoCloser(&readCloser{
+				Reader:  tr,
+				closers: []io.Closer{closerFunc(func() error { return closeReader(r) })},
+			}), nil
+		}
+	}
+}
+
+func (s *PutInGH) openZipEntry(r io.Reader, entry string) (io.Reader, error) {
+	defer closeReader(r)
+	err := os.MkdirAll(s.tmpDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(s.tmpDir, "archive-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	remove := closerFunc(func() error {
+		f.Close()
+		return os.Remove(f.Name())
+	})
+	size, err := io.Copy(f, r)
+	if err != nil {
+		remove.Close()
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		remove.Close()
+		return nil, err
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() || cleanArchiveEntry(file.Name) != entry {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			remove.Close()
+			return nil, err
+		}
+		return newReaderWithAutoCloser(&readCloser{
+			Reader:  rc,
+			closers: []io.Closer{rc, remove},
+		}), nil
+	}
+	remove.Close()
+	return nil, ErrNotFound
+}
@@ -0,0 +1,55 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// PutInAssetFromURL mirrors the content at srcURL into the asset name of release.
+// It is streamed straight into the upload when the source tells its length, or else through a temp file.
+// The token is only sent to srcURL when it is a GitHub host.
+func (s *PutInGH) PutInAssetFromURL(ctx context.Context, owner, repo, release, name, srcURL string) (string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", err
+	}
+	err := s.checkAllowed("asset", owner, repo)
+	if err != nil {
+		return "", err
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, "asset")
+	defer cancel()
+
+	resp, err := s.httpGet(ctx, srcURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: %s", srcURL, resp.Status)
+	}
+
+	size := resp.ContentLength
+	if size < 0 || s.requireChange || s.assetSkipIdentical {
+		return s.putInReleasesAsset(ctx, owner, repo, release, name, resp.Body)
+	}
+	if s.maxContentSize > 0 && size > s.maxContentSize {
+		return "", fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrContentTooLarge, srcURL, size, s.maxContentSize)
+	}
+	releaseID, _, err := s.prepareReleaseAsset(ctx, owner, repo, release, name, nil)
+	if err != nil {
+		return "", err
+	}
+	mediaType := mime.TypeByExtension(path.Ext(name))
+	if mediaType == "" {
+		mediaType = resp.Header.Get("Content-Type")
+	}
+	respAsset, err := s.uploadReleaseAssetReader(ctx, owner, repo, releaseID, name, resp.Body, size, mediaType)
+	if err != nil {
+		return "", err
+	}
+	return *respAsset.BrowserDownloadURL, nil
+}
@@ -0,0 +1,84 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInAssetFromURL(t *testing.T) {
+	content := strings.Repeat("content", 1<<10)
+	// the fake GitHub listens on 127.0.0.1, so the source is another host
+	var leaked atomic.Value
+	leaked.Store("")
+	source := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		leaked.Store(r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/sized":
+			rw.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			rw.Write([]byte(content))
+		case "/chunked":
+			rw.Write([]byte(content[:10]))
+			rw.(http.Flusher).Flush()
+			rw.Write([]byte(content[10:]))
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+	t.Cleanup(source.Close)
+	sourceURL := strings.Replace(source.URL, "127.0.0.1", "localhost", 1)
+
+	for _, name := range []string{"sized", "chunked"} {
+		t.Run(name, func(t *testing.T) {
+			srv, putter := putinghtest.NewServer(t)
+			var uploadLength atomic.Int64
+			handler := srv.Config.Handler
+			srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, "/api/uploads/") {
+					uploadLength.Store(r.ContentLength)
+				}
+				handler.ServeHTTP(rw, r)
+			})
+
+			ctx := context.Background()
+			_, err := putter.PutInAssetFromURL(ctx, owner, "repo", "v1", "name.txt", sourceURL+"/"+name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := uploadLength.Load(); got != int64(len(content)) {
+				t.Fatalf("uploaded with Content-Length %d, want %d", got, len(content))
+			}
+			if auth := leaked.Load().(string); auth != "" {
+				t.Fatalf("sent the token %q to %s", auth, sourceURL)
+			}
+			got, err := putter.GetBytes(ctx, "asset://"+owner+"/repo/v1/name.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != content {
+				t.Fatalf("got %d bytes, want %d", len(got), len(content))
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		_, putter := putinghtest.NewServer(t)
+		ctx := context.Background()
+		_, err := putter.PutInAssetFromURL(ctx, owner, "repo", "v1", "name.txt", sourceURL+"/missing")
+		if err == nil {
+			t.Fatal("mirrored a missing source")
+		}
+		_, err = putter.GetBytes(ctx, "asset://"+owner+"/repo/v1/name.txt")
+		if !errors.Is(err, putingh.ErrReleaseNotFound) {
+			t.Fatalf("got %v, the release must not have been created", err)
+		}
+	})
+}
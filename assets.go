@@ -0,0 +1,36 @@
+package putingh
+
+import (
+	"context"
+)
+
+// AssetInfo describes a release asset.
+type AssetInfo struct {
+	Name               string
+	Size               int
+	ContentType        string
+	DownloadCount      int
+	BrowserDownloadURL string
+}
+
+// ListReleaseAssets returns the assets of release without downloading them.
+func (s *PutInGH) ListReleaseAssets(ctx context.Context, owner, repo, release string) ([]AssetInfo, error) {
+	if err := s.checkAllowed("asset", owner, repo); err != nil {
+		return nil, err
+	}
+	repositoryRelease, err := s.getRelease(ctx, owner, repo, release)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]AssetInfo, 0, len(repositoryRelease.Assets))
+	for _, asset := range repositoryRelease.Assets {
+		infos = append(infos, AssetInfo{
+			Name:               asset.GetName(),
+			Size:               asset.GetSize(),
+			ContentType:        asset.GetContentType(),
+			DownloadCount:      asset.GetDownloadCount(),
+			BrowserDownloadURL: asset.GetBrowserDownloadURL(),
+		})
+	}
+	return infos, nil
+}
@@ -0,0 +1,75 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+)
+
+// readerLen returns the bytes left in r when r tells without being read, from Len or by seeking to its end.
+// start is where a seekable r is rewound to, -1 when it can not be.
+func readerLen(r io.Reader) (size, start int64, ok bool) {
+	if s, isSeeker := r.(io.Seeker); isSeeker {
+		cur, err := s.Seek(0, io.SeekCurrent)
+		if err == nil {
+			end, err := s.Seek(0, io.SeekEnd)
+			if err == nil {
+				_, err = s.Seek(cur, io.SeekStart)
+				if err == nil {
+					return end - cur, cur, true
+				}
+			}
+		}
+	}
+	if l, isLen := r.(interface{ Len() int }); isLen {
+		return int64(l.Len()), -1, true
+	}
+	return 0, -1, false
+}
+
+// putInReleasesAssetDirect uploads r straight from the reader when its length is known,
+// ok is false when r has to be spooled to a temp file first.
+func (s *PutInGH) putInReleasesAssetDirect(ctx context.Context, owner, repo, release, name string, r io.Reader) (_ string, ok bool, err error) {
+	size, start, ok := readerLen(r)
+	if !ok {
+		return "", false, nil
+	}
+	var open func() (io.Reader, int64, error)
+	if start >= 0 {
+		open = func() (io.Reader, int64, error) {
+			_, err := r.(io.Seeker).Seek(start, io.SeekStart)
+			if err != nil {
+				return nil, 0, err
+			}
+			return io.LimitReader(r, size), size, nil
+		}
+	} else if s.requireChange || s.assetSkipIdentical {
+		// comparing reads r, which can not be read again for the upload
+		return "", false, nil
+	}
+	if s.maxContentSize > 0 && size > s.maxContentSize {
+		return "", true, fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrContentTooLarge, name, size, s.maxContentSize)
+	}
+
+	releaseID, identical, err := s.prepareReleaseAsset(ctx, owner, repo, release, name, open)
+	if err != nil {
+		return "", true, err
+	}
+	if identical != nil {
+		return identical.GetBrowserDownloadURL(), true, nil
+	}
+	fmt.Fprintf(s.out, "uploading %s from the reader, %d bytes\n", name, size)
+	if open != nil {
+		r, _, err = open()
+		if err != nil {
+			return "", true, err
+		}
+	}
+	respAsset, err := s.uploadReleaseAssetReader(ctx, owner, repo, releaseID, name, r, size, mime.TypeByExtension(path.Ext(name)))
+	if err != nil {
+		return "", true, err
+	}
+	return respAsset.GetBrowserDownloadURL(), true, nil
+}
@@ -0,0 +1,47 @@
+package putingh
+
+import (
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// WithGitAuthorFromGitHubUser signs commits with the public name and email of the GitHub user login,
+// the email falls back to login@users.noreply.github.com. The user is looked up once.
+func WithGitAuthorFromGitHubUser(login string) Option {
+	return func(p *PutInGH) {
+		WithGitCommitOptions(func(owner, repo, branch, name, path string) *gogit.CommitOptions {
+			sig := p.gitHubUserSignature(login)
+			sig.When = time.Now()
+			return &gogit.CommitOptions{
+				Author: &sig,
+			}
+		})(p)
+	}
+}
+
+// gitHubUserSignature returns the signature of login, a failed lookup is retried by the next commit.
+func (s *PutInGH) gitHubUserSignature(login string) object.Signature {
+	s.authorMut.Lock()
+	defer s.authorMut.Unlock()
+	if s.author != nil {
+		return *s.author
+	}
+	sig := object.Signature{
+		Name:  login,
+		Email: login + "@users.noreply.github.com",
+	}
+	user, _, err := s.cliv3.Users.Get(s.ctx, login)
+	if err != nil {
+		return sig
+	}
+	if user.GetName() != "" {
+		sig.Name = user.GetName()
+	}
+	if user.GetEmail() != "" {
+		sig.Email = user.GetEmail()
+	}
+	s.author = &sig
+	return sig
+}
@@ -0,0 +1,53 @@
+package putingh_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGitAuthorFromGitHubUser(t *testing.T) {
+	users := map[string]map[string]string{
+		"octo":    {"login": "octo", "name": "Octo Cat", "email": "octo@example.com"},
+		"private": {"login": "private"},
+	}
+	for login, want := range map[string][2]string{
+		"octo":    {"Octo Cat", "octo@example.com"},
+		"private": {"private", "private@users.noreply.github.com"},
+	} {
+		t.Run(login, func(t *testing.T) {
+			srv, _ := putinghtest.NewServer(t)
+			var lookups atomic.Int64
+			handler := srv.Config.Handler
+			srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if user, ok := users[strings.TrimPrefix(r.URL.Path, "/api/v3/users/")]; ok {
+					lookups.Add(1)
+					json.NewEncoder(rw).Encode(user)
+					return
+				}
+				handler.ServeHTTP(rw, r)
+			})
+
+			putter := newPutter(t, srv, putingh.WithGitAuthorFromGitHubUser(login))
+			for _, content := range []string{"v1", "v2"} {
+				_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", strings.NewReader(content))
+				if err != nil {
+					t.Fatal(err)
+				}
+				author := headCommit(t, srv, "repo", "main").Author
+				if author.Name != want[0] || author.Email != want[1] {
+					t.Fatalf("got author %s <%s>, want %s <%s>", author.Name, author.Email, want[0], want[1])
+				}
+			}
+			if n := lookups.Load(); n != 1 {
+				t.Fatalf("looked the user up %d times, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// WithBareRepoPath writes git files through the existing bare repository at dir instead of a worktree per branch,
+// so objects are shared across branches. The commit is built in memory and pushed to the GitHub remote.
+// File modes, the commit signature, WithAutoSquash and WithForcePush apply as for worktree writes.
+func WithBareRepoPath(dir string) Option {
+	return func(p *PutInGH) {
+		p.bareRepoPath = dir
+	}
+}
+
+func (s *PutInGH) putInGitBare(ctx context.Context, owner, repo, branch, name string, r io.Reader) (string, plumbing.Hash, error) {
+	if isPullRef(branch) {
+		return "", plumbing.ZeroHash, fmt.Errorf("%s is read-only", gitRemoteRef(branch))
+	}
+	if name == "" || path.Clean("/" + name)[1:] != name {
+		return "", plumbing.ZeroHash, fmt.Errorf("%w: %q", ErrInvalidPath, name)
+	}
+	err := s.checkRepoVisibility(ctx, owner, repo)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	// the repository is shared by every branch, and with WithWorktreeLock by other processes
+	unlock, err := s.lockBareRepo(ctx)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	defer unlock()
+	repository, err := plainOpenBareGit(s.bareRepoPath)
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("%w: %s", err, s.bareRepoPath)
+	}
+	parent, err := s.fetchBare(ctx, repository, owner, repo, branch)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	var parentTree *object.Tree
+	var parents []plumbing.Hash
+	if parent != nil {
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+		parents = []plumbing.Hash{parent.Hash}
+	}
+
+	blob, err := s.writeBlobFromReader(repository, s.limitReader(r))
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	rawURL := s.gitURL(owner, repo) + "/raw/" + branch + "/" + name
+	if parentTree != nil {
+		entry, err := parentTree.FindEntry(name)
+		if err == nil && entry.Hash == blob {
+			if s.requireChange {
+				return "", plumbing.ZeroHash, fmt.Errorf("%w: %s", ErrNoChange, name)
+			}
+			return rawURL, parent.Hash, nil
+		}
+	}
+
+	tree, err := writeTreeWith(repository, parentTree, strings.Split(name, "/"), blob)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	opt := s.gitCommitOptions(owner, repo, branch, name, s.bareRepoPath)
+	opt.Parents = parents
+	// fills in the author and committer like a worktree commit does
+	err = opt.Validate(repository)
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("git commit: %w", err)
+	}
+	commit := &object.Commit{
+		Author:       *opt.Author,
+		Committer:    *opt.Committer,
+		Message:      s.commitMessage(owner, repo, branch, name, s.bareRepoPath),
+		TreeHash:     tree,
+		ParentHashes: parents,
+	}
+	obj := repository.Storer.NewEncodedObject()
+	err = commit.Encode(obj)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	hash, err := repository.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	refName := plumbing.NewBranchReferenceName(branch)
+	err = repository.Storer.SetReference(plumbing.NewHashReference(refName, hash))
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if s.commitSigner != nil {
+		_, err = s.signCommit(repository, branch, hash)
+		if err != nil {
+			return "", plumbing.ZeroHash, fmt.Errorf("git sign: %w", err)
+		}
+	}
+	force, err := s.pushForce(repository, branch)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	err = s.retryGit(ctx, func() error {
+		pushCtx, cancel := s.gitRequestContext(ctx)
+		defer cancel()
+		return repository.PushContext(pushCtx, &gogit.PushOptions{
+			Auth:       s.gitBasicAuth(ctx, owner),
+			RemoteName: s.gitRemoteName(branch),
+			RefSpecs:   []gogitconfig.RefSpec{gogitconfig.RefSpec(refName + ":" + refName)},
+			Progress:   s.out,
+			Force:      force,
+		})
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return "", plumbing.ZeroHash, fmt.Errorf("git push: %w", err)
+	}
+	return rawURL, gitBranchHead(repository, branch), nil
+}
+
+// fetchBare fetches branch into the bare repository and returns its tip, nil when the branch does not exist yet.
+func (s *PutInGH) fetchBare(ctx context.Context, repository *gogit.Repository, owner, repo, branch string) (*object.Commit, error) {
+	remoteName := s.gitRemoteName(branch)
+	remoteRefName := plumbing.NewRemoteReferenceName(remoteName, branch)
+	fetch := []gogitconfig.RefSpec{
+		gogitconfig.RefSpec(fmt.Sprintf("+%s:%s", gitRemoteRef(branch), remoteRefName)),
+	}
+	remote, err := repository.Remote(remoteName)
+	if err != nil {
+		if !errors.Is(err, gogit.ErrRemoteNotFound) {
+			return nil, err
+		}
+		remote, err = repository.CreateRemote(&gogitconfig.RemoteConfig{
+			Name:  remoteName,
+			URLs:  []string{s.gitURL(owner, repo)},
+			Fetch: fetch,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.retryGit(ctx, func() error {
+		fetchCtx, cancel := s.gitRequestContext(ctx)
+		defer cancel()
+		return remote.FetchContext(fetchCtx, &gogit.FetchOptions{
+			RemoteName: remoteName,
+			RefSpecs:   fetch,
+			Depth:      s.gitFetchDepth,
+			Progress:   s.out,
+			Auth:       s.gitBasicAuth(ctx, owner),
+		})
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		var noMatchingRefSpecError gogit.NoMatchingRefSpecError
+		if !errors.As(err, &noMatchingRefSpecError) {
+			return nil, fmt.Errorf("git fetch: %w", err)
+		}
+	}
+	ref, err := repository.Storer.Reference(remoteRefName)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			base, err := s.fetchBranchBase(ctx, repository, remote, owner, repo, branch)
+			if err != nil || base.IsZero() {
+				return nil, err
+			}
+			return repository.CommitObject(base)
+		}
+		return nil, fmt.Errorf("reference: %w", err)
+	}
+	return repository.CommitObject(ref.Hash())
+}
+
+// writeTreeWith stores a copy of tree with the file at parts set to blob and returns its hash,
+// a nil tree is empty.
+func writeTreeWith(repository *gogit.Repository, tree *object.Tree, parts []string, blob plumbing.Hash) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	var sub *object.Tree
+	// an existing file keeps its mode, such as the executable bit
+	mode := filemode.Regular
+	if tree != nil {
+		for _, entry := range tree.Entries {
+			if entry.Name != parts[0] {
+				entries = append(entries, entry)
+				continue
+			}
+			if entry.Mode == filemode.Executable {
+				mode = entry.Mode
+			}
+			if len(parts) > 1 && entry.Mode == filemode.Dir {
+				t, err := tree.Tree(entry.Name)
+				if err != nil {
+					return plumbing.ZeroHash, err
+				}
+				sub = t
+			}
+		}
+	}
+
+	entry := object.TreeEntry{
+		Name: parts[0],
+		Mode: mode,
+		Hash: blob,
+	}
+	if len(parts) > 1 {
+		hash, err := writeTreeWith(repository, sub, parts[1:], blob)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entry.Mode = filemode.Dir
+		entry.Hash = hash
+	}
+	entries = append(entries, entry)
+
+	// git orders directories as if their name ended with a slash
+	sortName := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortName(entries[i]) < sortName(entries[j])
+	})
+
+	obj := repository.Storer.NewEncodedObject()
+	err := (&object.Tree{Entries: entries}).Encode(obj)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repository.Storer.SetEncodedObject(obj)
+}
@@ -0,0 +1,102 @@
+package putingh_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInGitBareKeepsExecutableMode(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"bin/run.sh": "exec:#!/bin/sh\n",
+	})
+	putter := newPutter(t, srv, putingh.WithBareRepoPath(bareRepo(t)))
+	_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/bin/run.sh", strings.NewReader("#!/bin/sh\necho\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := headCommit(t, srv, "repo", "main").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := tree.FindEntry("bin/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Mode != filemode.Executable {
+		t.Fatalf("got mode %s, want %s", entry.Mode, filemode.Executable)
+	}
+}
+
+func TestPutInGitBareSignatureFromConfig(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	dir := bareRepo(t)
+	repository, err := gogit.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repository.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "config"
+	cfg.User.Email = "config@example.com"
+	err = repository.SetConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without an author in the options it comes from the repository config, as for worktree commits
+	putter := newPutter(t, srv,
+		putingh.WithBareRepoPath(dir),
+		putingh.WithGitCommitOptions(func(owner, repo, branch, name, path string) *gogit.CommitOptions {
+			return nil
+		}),
+	)
+	_, err = putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := headCommit(t, srv, "repo", "main")
+	for _, signature := range []struct{ name, email string }{
+		{commit.Author.Name, commit.Author.Email},
+		{commit.Committer.Name, commit.Committer.Email},
+	} {
+		if signature.name != "config" || signature.email != "config@example.com" {
+			t.Fatalf("got %s <%s>, want config <config@example.com>", signature.name, signature.email)
+		}
+	}
+}
+
+func TestPutInGitBareAutoSquash(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithBareRepoPath(bareRepo(t)), putingh.WithAutoSquash(2))
+	ctx := context.Background()
+	for i := 1; i <= 4; i++ {
+		_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/name.txt", strings.NewReader("v"+strconv.Itoa(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit := headCommit(t, srv, "repo", "main")
+	n := 1
+	for commit.NumParents() != 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commit = parent
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("got %d commits, want 2", n)
+	}
+}
@@ -0,0 +1,108 @@
+package putingh
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// GitBatch accumulates files for a single branch and writes them in one commit.
+type GitBatch struct {
+	s      *PutInGH
+	owner  string
+	repo   string
+	branch string
+
+	names   []string
+	readers []io.Reader
+}
+
+// Batch returns a GitBatch that commits to owner/repo on branch.
+func (s *PutInGH) Batch(owner, repo, branch string) *GitBatch {
+	return &GitBatch{
+		s:      s,
+		owner:  owner,
+		repo:   repo,
+		branch: branch,
+	}
+}
+
+// Add queues r to be written to name, the reader is consumed by Commit.
+func (b *GitBatch) Add(name string, r io.Reader) {
+	b.names = append(b.names, name)
+	b.readers = append(b.readers, r)
+}
+
+// Commit writes all queued files, commits and pushes once, and returns the raw URL of each file.
+// An empty batch is a no-op and returns nil before the read-only and allowlist checks.
+func (b *GitBatch) Commit(ctx context.Context) ([]string, error) {
+	if len(b.names) == 0 {
+		return nil, nil
+	}
+	if err := b.s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := b.s.checkAllowed("git", b.owner, b.repo); err != nil {
+		return nil, err
+	}
+	names, readers := b.names, b.readers
+	b.names, b.readers = nil, nil
+
+	s := b.s
+	staged := make([]string, 0, len(names))
+	branch, _, err := s.updateGit(ctx, b.owner, b.repo, b.branch, func(dir string, _ *gogit.Repository) (string, []string, error) {
+		seen := map[string]bool{}
+		for i, name := range names {
+			_, err := s.writeGitFile(dir, name, readers[i])
+			if err != nil {
+				return "", nil, err
+			}
+			if !seen[name] {
+				seen[name] = true
+				staged = append(staged, name)
+			}
+		}
+		return strings.Join(staged, ", "), staged, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(staged))
+	for _, name := range staged {
+		urls = append(urls, s.gitURL(b.owner, b.repo)+"/raw/"+branch+"/"+name)
+	}
+	return urls, nil
+}
+
+// updateGit fetches the worktree of branch, lets update change it, and commits and pushes
+// the paths update returns in one commit for name. It returns the resolved branch and the changed paths.
+func (s *PutInGH) updateGit(ctx context.Context, owner, repo, branch string, update func(dir string, repository *gogit.Repository) (name string, names []string, err error)) (string, []string, error) {
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, "git")
+	defer cancel()
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return "", nil, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return "", nil, err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return "", nil, err
+	}
+	name, names, err := update(dir, repository)
+	if err != nil {
+		return "", nil, err
+	}
+	changed, _, err := s.commitGit(ctx, repository, owner, repo, branch, name, dir, names)
+	if err != nil {
+		return "", nil, err
+	}
+	return branch, changed, nil
+}
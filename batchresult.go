@@ -0,0 +1,64 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+)
+
+// BatchResult is the outcome of one item of GetFromBatch or PutInBatch,
+// Reader is set for a get and URL for a put when Err is nil.
+type BatchResult struct {
+	URI    string
+	URL    string
+	Reader io.Reader
+	Err    error
+}
+
+// GetFromBatch gets each of uris in order, a failing item does not stop the others.
+// The error is only non-nil when every item failed, the results always hold the error of each item.
+func (s *PutInGH) GetFromBatch(ctx context.Context, uris []string) ([]BatchResult, error) {
+	results := make([]BatchResult, 0, len(uris))
+	for _, uri := range uris {
+		r, err := s.GetFrom(ctx, uri)
+		results = append(results, BatchResult{
+			URI:    uri,
+			Reader: r,
+			Err:    err,
+		})
+	}
+	return results, batchError(results)
+}
+
+// PutInBatch puts each reader in its uri sorted by uri, a failing item does not stop the others.
+// The error is only non-nil when every item failed, the results always hold the error of each item.
+func (s *PutInGH) PutInBatch(ctx context.Context, contents map[string]io.Reader) ([]BatchResult, error) {
+	uris := make([]string, 0, len(contents))
+	for uri := range contents {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	results := make([]BatchResult, 0, len(uris))
+	for _, uri := range uris {
+		u, err := s.PutIn(ctx, uri, contents[uri])
+		results = append(results, BatchResult{
+			URI: uri,
+			URL: u,
+			Err: err,
+		})
+	}
+	return results, batchError(results)
+}
+
+// batchError joins the errors of results when none of them succeeded.
+func batchError(results []BatchResult) error {
+	errs := make([]error, 0, len(results))
+	for _, result := range results {
+		if result.Err == nil {
+			return nil
+		}
+		errs = append(errs, result.Err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestBatchPartialFailure(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	good := "git://" + owner + "/repo/main/good.txt"
+	escaping := "git://" + owner + "/repo/main/../escape.txt"
+	gist := "gist://" + owner + "/*/good.txt"
+	unsupported := "nope://" + owner + "/good.txt"
+
+	results, err := putter.PutInBatch(ctx, map[string]io.Reader{
+		good:        strings.NewReader("good"),
+		escaping:    strings.NewReader("escape"),
+		gist:        strings.NewReader("gist"),
+		unsupported: strings.NewReader("nope"),
+	})
+	if err != nil {
+		t.Fatalf("got %v, want nil while some items succeeded", err)
+	}
+	wantOrder := []string{gist, escaping, good, unsupported}
+	if len(results) != len(wantOrder) {
+		t.Fatalf("got %d results, want %d", len(results), len(wantOrder))
+	}
+	for i, result := range results {
+		if result.URI != wantOrder[i] {
+			t.Fatalf("got %q at %d, want %q", result.URI, i, wantOrder[i])
+		}
+		failed := result.URI == escaping || result.URI == unsupported
+		if failed != (result.Err != nil) {
+			t.Fatalf("%s: got error %v", result.URI, result.Err)
+		}
+		if !failed && result.URL == "" {
+			t.Fatalf("%s: got no URL", result.URI)
+		}
+	}
+	if !errors.Is(results[1].Err, putingh.ErrInvalidPath) {
+		t.Fatalf("got %v, want ErrInvalidPath", results[1].Err)
+	}
+
+	results, err = putter.GetFromBatch(ctx, []string{good, "git://" + owner + "/repo/main/missing.txt", gist})
+	if err != nil {
+		t.Fatalf("got %v, want nil while some items succeeded", err)
+	}
+	for i, want := range []string{"good", "", "gist"} {
+		if want == "" {
+			if results[i].Err == nil {
+				t.Fatalf("%s: got no error", results[i].URI)
+			}
+			continue
+		}
+		if results[i].Err != nil {
+			t.Fatalf("%s: %v", results[i].URI, results[i].Err)
+		}
+		got, err := io.ReadAll(results[i].Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("%s: got %q, want %q", results[i].URI, got, want)
+		}
+	}
+
+	// only when every item fails is there an error, holding each of them
+	results, err = putter.PutInBatch(ctx, map[string]io.Reader{
+		escaping:    strings.NewReader("escape"),
+		unsupported: strings.NewReader("nope"),
+	})
+	if err == nil {
+		t.Fatal("got nil, want an error when every item failed")
+	}
+	for _, result := range results {
+		if !errors.Is(err, result.Err) {
+			t.Fatalf("%v does not hold the error of %s", err, result.URI)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// WithDefaultBranch sets the branch used when the branch of a git URI is empty or the placeholder.
+// It takes precedence over looking up the default branch of the repository,
+// which is done when it is not set.
+func WithDefaultBranch(branch string) Option {
+	return func(p *PutInGH) {
+		p.defaultBranch = branch
+	}
+}
+
+// WithDefaultBranchPlaceholder makes the branch segment placeholder, e.g. "default",
+// resolve like an empty one. There is no placeholder by default, so every named branch is used as is.
+func WithDefaultBranchPlaceholder(placeholder string) Option {
+	return func(p *PutInGH) {
+		p.branchPlaceholder = placeholder
+	}
+}
+
+func (s *PutInGH) isDefaultBranch(branch string) bool {
+	return branch == "" || (s.branchPlaceholder != "" && branch == s.branchPlaceholder)
+}
+
+// resolveBranch replaces the branch placeholder with the configured branch
+// or the default branch of the repository.
+func (s *PutInGH) resolveBranch(ctx context.Context, owner, repo, branch string) (string, error) {
+	if !s.isDefaultBranch(branch) {
+		return branch, nil
+	}
+	if s.defaultBranch != "" {
+		return s.defaultBranch, nil
+	}
+	key := owner + "/" + repo
+	if b, ok := s.repoDefaultBranches.Load(key); ok {
+		return b.(string), nil
+	}
+	repository, response, err := s.cliv3.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("repository %s: %w", key, ErrNotFound)
+		}
+		return "", err
+	}
+	b := repository.GetDefaultBranch()
+	if b == "" {
+		return "", fmt.Errorf("repository %s has no default branch", key)
+	}
+	s.repoDefaultBranches.Store(key, b)
+	return b, nil
+}
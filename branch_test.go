@@ -0,0 +1,57 @@
+package putingh_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestDefaultBranch(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	ctx := context.Background()
+	putter := newPutter(t, srv, putingh.WithDefaultBranch("main"))
+
+	_, err := putter.PutIn(ctx, "git://"+owner+"/repo//name.txt", strings.NewReader("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.PutIn(ctx, "git://"+owner+"/repo/default/name.txt", strings.NewReader("named"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "main" {
+		t.Fatalf("a branch named default overwrote main: got %q", got)
+	}
+	got, err = putter.GetBytes(ctx, "git://"+owner+"/repo/default/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "named" {
+		t.Fatalf("got %q, want %q", got, "named")
+	}
+}
+
+func TestDefaultBranchPlaceholder(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	ctx := context.Background()
+	putter := newPutter(t, srv, putingh.WithDefaultBranch("main"), putingh.WithDefaultBranchPlaceholder("default"))
+
+	_, err := putter.PutIn(ctx, "git://"+owner+"/repo/default/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+}
@@ -0,0 +1,107 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// WithBranchBaseResolver starts branches that do not exist yet from the head of the branch returned by fn,
+// instead of an orphan commit. An empty branch from fn means the default branch of the repository.
+func WithBranchBaseResolver(fn func(owner, repo string) string) Option {
+	return func(p *PutInGH) {
+		p.branchBase = fn
+	}
+}
+
+type branchBaseContextKey struct{}
+
+// withBranchBase overrides the base of branches created with ctx.
+func withBranchBase(ctx context.Context, base string) context.Context {
+	return context.WithValue(ctx, branchBaseContextKey{}, base)
+}
+
+// fetchBranchBase fetches the base of the new branch through remote and returns its head,
+// zero when no base is configured or it does not exist either.
+func (s *PutInGH) fetchBranchBase(ctx context.Context, repository *gogit.Repository, remote *gogit.Remote, owner, repo, branch string) (plumbing.Hash, error) {
+	base, ok := ctx.Value(branchBaseContextKey{}).(string)
+	if !ok {
+		if s.branchBase == nil {
+			return plumbing.ZeroHash, nil
+		}
+		var err error
+		base, err = s.resolveBranch(ctx, owner, repo, s.branchBase(owner, repo))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	if base == branch {
+		return plumbing.ZeroHash, nil
+	}
+
+	baseRefName := plumbing.ReferenceName("refs/putingh/base/" + base)
+	fetch := []gogitconfig.RefSpec{
+		gogitconfig.RefSpec(fmt.Sprintf("+%s:%s", gitRemoteRef(base), baseRefName)),
+	}
+	err := s.retryGit(ctx, func() error {
+		fetchCtx, cancel := s.gitRequestContext(ctx)
+		defer cancel()
+		return remote.FetchContext(fetchCtx, &gogit.FetchOptions{
+			RemoteName: remote.Config().Name,
+			RefSpecs:   fetch,
+			Depth:      s.gitFetchDepth,
+			Progress:   s.out,
+			Auth:       s.gitBasicAuth(ctx, owner),
+		})
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		var noMatchingRefSpecError gogit.NoMatchingRefSpecError
+		if errors.As(err, &noMatchingRefSpecError) || errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return plumbing.ZeroHash, nil
+		}
+		return plumbing.ZeroHash, fmt.Errorf("git fetch %s: %w", base, err)
+	}
+	ref, err := repository.Storer.Reference(baseRefName)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return plumbing.ZeroHash, nil
+		}
+		return plumbing.ZeroHash, fmt.Errorf("reference: %w", err)
+	}
+	return ref.Hash(), nil
+}
+
+// checkoutBranchBase points the new local branch at its base and checks it out,
+// a local branch left by an earlier failed push is kept.
+func (s *PutInGH) checkoutBranchBase(ctx context.Context, repository *gogit.Repository, remote *gogit.Remote, owner, repo, branch string) error {
+	refName := plumbing.NewBranchReferenceName(branch)
+	_, err := repository.Storer.Reference(refName)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+	hash, err := s.fetchBranchBase(ctx, repository, remote, owner, repo, branch)
+	if err != nil || hash.IsZero() {
+		return err
+	}
+	err = repository.Storer.SetReference(plumbing.NewHashReference(refName, hash))
+	if err != nil {
+		return fmt.Errorf("setReference: %w", err)
+	}
+	work, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+	err = s.resetGit(repository, work, hash)
+	if err != nil {
+		return fmt.Errorf("git reset: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package putingh_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestBranchBaseResolver(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "main"})
+	pushGit(t, srv, "repo", "develop", map[string]string{"README.md": "develop"})
+	for name, base := range map[string]string{
+		// empty is the default branch
+		"default": "",
+		"develop": "develop",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var asked string
+			putter := newPutter(t, srv, putingh.WithBranchBaseResolver(func(owner, repo string) string {
+				asked = owner + "/" + repo
+				return base
+			}))
+			if base == "" {
+				base = "main"
+			}
+			baseHead := headCommit(t, srv, "repo", base)
+
+			ctx := context.Background()
+			branch := "feature-" + name
+			_, err := putter.PutIn(ctx, "git://"+owner+"/repo/"+branch+"/new.txt", strings.NewReader("new"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if asked != owner+"/repo" {
+				t.Fatalf("the resolver was asked for %q", asked)
+			}
+			head := headCommit(t, srv, "repo", branch)
+			if len(head.ParentHashes) != 1 || head.ParentHashes[0] != baseHead.Hash {
+				t.Fatalf("got parents %v, want %s", head.ParentHashes, baseHead.Hash)
+			}
+			got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/"+branch+"/README.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != base {
+				t.Fatalf("got %q, want the README of %s", got, base)
+			}
+		})
+	}
+
+	t.Run("without", func(t *testing.T) {
+		putter := newPutter(t, srv)
+		_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/orphan/new.txt", strings.NewReader("new"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		head := headCommit(t, srv, "repo", "orphan")
+		if len(head.ParentHashes) != 0 {
+			t.Fatalf("got parents %v, want an orphan commit", head.ParentHashes)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package putingh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// WithMaxGetBytes limits the content GetBytes reads, exceeding it fails with ErrContentTooLarge.
+func WithMaxGetBytes(size int64) Option {
+	return func(p *PutInGH) {
+		p.maxGetBytes = size
+	}
+}
+
+// GetBytes returns the whole content of uri and closes the underlying reader.
+func (s *PutInGH) GetBytes(ctx context.Context, uri string) ([]byte, error) {
+	r, err := s.GetFrom(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader(r)
+	if s.maxGetBytes <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, s.maxGetBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxGetBytes {
+		return nil, fmt.Errorf("%w: %s is over the limit of %d bytes", ErrContentTooLarge, uri, s.maxGetBytes)
+	}
+	return data, nil
+}
+
+// WithAssetBufferSize uploads assets of up to size bytes given to PutBytes straight from memory,
+// larger ones go through a temp file like PutIn.
+func WithAssetBufferSize(size int64) Option {
+	return func(p *PutInGH) {
+		p.assetBufferSize = size
+	}
+}
+
+// PutBytes writes data to uri like PutIn.
+func (s *PutInGH) PutBytes(ctx context.Context, uri string, data []byte) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "asset" || int64(len(data)) > s.assetBufferSize || s.lineEndingOf(u.Path) != LineEndingAsIs {
+		return s.PutIn(ctx, uri, bytes.NewReader(data))
+	}
+	start := time.Now()
+	raw, err := s.putBytesAsset(ctx, u, data)
+	err = uriError(uri, err)
+	if s.events != nil {
+		s.emitEvent(EventPut, uri, int64(len(data)), start, err)
+	}
+	return raw, err
+}
+
+// putBytesAsset uploads data to the asset u straight from memory.
+func (s *PutInGH) putBytesAsset(ctx context.Context, u *url.URL, data []byte) (string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", err
+	}
+	err := s.checkURIAllowed(u)
+	if err != nil {
+		return "", err
+	}
+	sl := strings.SplitN(u.Path, "/", 4)
+	if len(sl) != 4 {
+		return "", fmt.Errorf("%q not match asset://owner/repository/release/name", u.String())
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, u.Scheme)
+	defer cancel()
+	return s.putInReleasesAssetBytes(ctx, u.Host, sl[1], sl[2], sl[3], data)
+}
+
+func (s *PutInGH) putInReleasesAssetBytes(ctx context.Context, owner, repo, release, name string, data []byte) (string, error) {
+	if s.maxContentSize > 0 && int64(len(data)) > s.maxContentSize {
+		return "", fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrContentTooLarge, name, len(data), s.maxContentSize)
+	}
+	releaseID, identical, err := s.prepareReleaseAsset(ctx, owner, repo, release, name, func() (io.Reader, int64, error) {
+		return bytes.NewReader(data), int64(len(data)), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if identical != nil {
+		return identical.GetBrowserDownloadURL(), nil
+	}
+	respAsset, err := s.uploadReleaseAssetReader(ctx, owner, repo, releaseID, name, bytes.NewReader(data), int64(len(data)), mime.TypeByExtension(path.Ext(name)))
+	if err != nil {
+		return "", err
+	}
+	return *respAsset.BrowserDownloadURL, nil
+}
@@ -0,0 +1,159 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// closeTrackingTransport counts the response bodies it returned that are not closed yet.
+type closeTrackingTransport struct {
+	base http.RoundTripper
+	open atomic.Int64
+}
+
+func (c *closeTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := c.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	c.open.Add(1)
+	resp.Body = &trackedBody{ReadCloser: resp.Body, open: &c.open}
+	return resp, nil
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	open   *atomic.Int64
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	if b.closed.CompareAndSwap(false, true) {
+		b.open.Add(-1)
+	}
+	return b.ReadCloser.Close()
+}
+
+func TestGetBytes(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	content := strings.Repeat("0123456789abcdef", 1<<12)
+	size := int64(len(content))
+	uris := []string{
+		"git://" + owner + "/repo/main/name.txt",
+		"gist://" + owner + "/*/name.txt",
+		"asset://" + owner + "/repo/v1/name.txt",
+	}
+	for _, uri := range uris {
+		_, err := putter.PutIn(ctx, uri, strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracking := &closeTrackingTransport{}
+	withTracking := putingh.WithHTTPClient(func(cli *http.Client) *http.Client {
+		c := *cli
+		tracking.base = cli.Transport
+		c.Transport = tracking
+		return &c
+	})
+	for _, uri := range uris {
+		t.Run(uri, func(t *testing.T) {
+			// streamed gist files are read from a response body that has to be closed
+			reader := newPutter(t, srv, withTracking, putingh.WithGistStreamThreshold(1))
+			got, err := reader.GetBytes(ctx, uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != content {
+				t.Fatalf("got %d bytes, want %d", len(got), size)
+			}
+			if n := tracking.open.Load(); n != 0 {
+				t.Fatalf("left %d response bodies open", n)
+			}
+
+			limited := newPutter(t, srv, withTracking, putingh.WithGistStreamThreshold(1), putingh.WithMaxGetBytes(size))
+			_, err = limited.GetBytes(ctx, uri)
+			if err != nil {
+				t.Fatalf("content of the limit: %v", err)
+			}
+			// the read stops short of EOF, with nothing closing the body on its own
+			limited = newPutter(t, srv, withTracking, putingh.WithGistStreamThreshold(1), putingh.WithMaxGetBytes(size/2))
+			_, err = limited.GetBytes(ctx, uri)
+			if !errors.Is(err, putingh.ErrContentTooLarge) {
+				t.Fatalf("got %v, want ErrContentTooLarge", err)
+			}
+			if n := tracking.open.Load(); n != 0 {
+				t.Fatalf("left %d response bodies open", n)
+			}
+		})
+	}
+}
+
+func TestPutBytesAsset(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	var contentLength int64
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/uploads/") {
+			contentLength = r.ContentLength
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	var staged []string
+	staging := func(name string) (*os.File, func(), error) {
+		staged = append(staged, name)
+		f, err := os.CreateTemp(t.TempDir(), name)
+		return f, nil, err
+	}
+	putter := newPutter(t, srv,
+		putingh.WithAssetBufferSize(1<<10),
+		putingh.WithAssetStagingFunc(staging),
+	)
+	ctx := context.Background()
+
+	for name, size := range map[string]int{
+		"small.bin": 1 << 10,
+		// over the buffer size it goes through PutIn, which streams a reader of known length too
+		"large.bin": 1 << 16,
+	} {
+		data := bytes.Repeat([]byte("x"), size)
+		_, err := putter.PutBytes(ctx, "asset://"+owner+"/repo/v1/"+name, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentLength != int64(size) {
+			t.Fatalf("%s: uploaded with Content-Length %d, want %d", name, contentLength, size)
+		}
+		got, err := putter.GetBytes(ctx, "asset://"+owner+"/repo/v1/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("%s: got %d bytes back, want %d", name, len(got), size)
+		}
+	}
+	if len(staged) != 0 {
+		t.Fatalf("staged %v on disk", staged)
+	}
+
+	// only a reader of unknown length is spooled to disk
+	_, err := putter.PutIn(ctx, "asset://"+owner+"/repo/v1/spooled.bin", struct{ io.Reader }{strings.NewReader("spooled")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(staged) != 1 || staged[0] != "spooled.bin" {
+		t.Fatalf("staged %v, want spooled.bin", staged)
+	}
+}
@@ -0,0 +1,262 @@
+package putingh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// WithDiskCache caches GetFrom results in dir, an entry is served without any request within ttl
+// and revalidated with its ETag afterwards.
+func WithDiskCache(dir string, ttl time.Duration) Option {
+	return func(p *PutInGH) {
+		p.diskCacheDir = dir
+		p.diskCacheTTL = ttl
+	}
+}
+
+// WithDiskCacheMaxBytes evicts the least recently used entries of the disk cache beyond size bytes.
+func WithDiskCacheMaxBytes(size int64) Option {
+	return func(p *PutInGH) {
+		p.diskCacheMaxBytes = size
+	}
+}
+
+type cacheMeta struct {
+	URI     string    `json:"uri"`
+	ETag    string    `json:"etag"`
+	Fetched time.Time `json:"fetched"`
+}
+
+func (s *PutInGH) getFromCache(ctx context.Context, uri string, u *url.URL) (io.Reader, error) {
+	sum := sha256.Sum256([]byte(uri))
+	key := hex.EncodeToString(sum[:])
+	dataFile := filepath.Join(s.diskCacheDir, key+".data")
+	metaFile := filepath.Join(s.diskCacheDir, key+".json")
+
+	meta, ok := readCacheMeta(metaFile, uri)
+	if ok && time.Since(meta.Fetched) < s.diskCacheTTL {
+		if r, err := openCacheData(dataFile); err == nil {
+			return r, nil
+		}
+	}
+
+	var cachedETag string
+	if ok {
+		cachedETag = meta.ETag
+	}
+	etag, notModified, err := s.contentETag(ctx, u, cachedETag)
+	if err != nil {
+		return nil, err
+	}
+	if notModified {
+		if r, err := openCacheData(dataFile); err == nil {
+			meta.Fetched = time.Now()
+			writeCacheMeta(metaFile, meta)
+			return r, nil
+		}
+	}
+
+	r, err := s.getFrom(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader(r)
+	err = os.MkdirAll(s.diskCacheDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	err = writeFileAtomic(dataFile, r)
+	if err != nil {
+		return nil, err
+	}
+	err = writeCacheMeta(metaFile, cacheMeta{
+		URI:     uri,
+		ETag:    etag,
+		Fetched: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.evictDiskCache(key)
+	return openCacheData(dataFile)
+}
+
+// contentETag returns a version of the content behind u, notModified is true when it is still etag.
+// An empty result means the content can not be revalidated.
+func (s *PutInGH) contentETag(ctx context.Context, u *url.URL, etag string) (string, bool, error) {
+	var path string
+	switch u.Scheme {
+	case "git":
+		repo, branch, _, ok := splitGitPath(u.Path)
+		if !ok {
+			return "", false, nil
+		}
+		branch, err := s.resolveBranch(ctx, u.Host, repo, branch)
+		if err != nil {
+			return "", false, nil
+		}
+		return s.gitETag(ctx, u.Host, repo, branch, etag)
+	case "asset", "releasenotes":
+		sl := strings.SplitN(u.Path, "/", 4)
+		if len(sl) < 3 || isReleaseAlias(sl[2]) {
+			return "", false, nil
+		}
+		path = "repos/" + u.Host + "/" + sl[1] + "/releases/tags/" + s.releaseTagOf(sl[2])
+	case "gist":
+		sl := strings.SplitN(u.Path, "/", 3)
+		if len(sl) != 3 || sl[1] == anyFile {
+			return "", false, nil
+		}
+		path = "gists/" + sl[1]
+	default:
+		return "", false, nil
+	}
+
+	req, err := s.cliv3.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	response, err := s.cliv3.Do(ctx, req, nil)
+	if response != nil && response.StatusCode == http.StatusNotModified {
+		return etag, true, nil
+	}
+	if err != nil {
+		// let the read itself report the failure
+		return "", false, nil
+	}
+	return response.Header.Get("ETag"), false, nil
+}
+
+// gitETag uses the commit at the tip of branch as the version.
+func (s *PutInGH) gitETag(ctx context.Context, owner, repo, branch, etag string) (string, bool, error) {
+	remote := gogit.NewRemote(memory.NewStorage(), &gogitconfig.RemoteConfig{
+		Name: s.gitRemoteName(branch),
+		URLs: []string{s.gitURL(owner, repo)},
+	})
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{
+		Auth: s.gitBasicAuth(ctx, owner),
+	})
+	if err != nil {
+		return "", false, nil
+	}
+	for _, ref := range refs {
+		if ref.Name().String() == gitRemoteRef(branch) {
+			hash := ref.Hash().String()
+			return hash, hash == etag, nil
+		}
+	}
+	return "", false, nil
+}
+
+func readCacheMeta(name, uri string) (cacheMeta, bool) {
+	var meta cacheMeta
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return meta, false
+	}
+	err = json.Unmarshal(data, &meta)
+	if err != nil || meta.URI != uri {
+		return meta, false
+	}
+	return meta, true
+}
+
+func writeCacheMeta(name string, meta cacheMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(name, strings.NewReader(string(data)))
+}
+
+func openCacheData(name string) (io.Reader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	os.Chtimes(name, now, now)
+	return newReaderWithAutoCloser(f), nil
+}
+
+// writeFileAtomic writes r to a temp file next to name and renames it into place.
+func writeFileAtomic(name string, r io.Reader) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	err = os.Rename(f.Name(), name)
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// evictDiskCache removes the least recently used entries except keep until the cache fits in diskCacheMaxBytes.
+func (s *PutInGH) evictDiskCache(keep string) {
+	if s.diskCacheMaxBytes <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(s.diskCacheDir)
+	if err != nil {
+		return
+	}
+	var infos []os.FileInfo
+	var total int64
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".data") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+		total += info.Size()
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+	for _, info := range infos {
+		if total <= s.diskCacheMaxBytes {
+			break
+		}
+		key := strings.TrimSuffix(info.Name(), ".data")
+		if key == keep {
+			continue
+		}
+		os.Remove(filepath.Join(s.diskCacheDir, key+".json"))
+		if os.Remove(filepath.Join(s.diskCacheDir, info.Name())) == nil {
+			total -= info.Size()
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package putingh_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// gistRequests records the gist API requests and their responses.
+type gistRequests struct {
+	mut      sync.Mutex
+	requests []string
+}
+
+func (g *gistRequests) take() []string {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	requests := g.requests
+	g.requests = nil
+	return requests
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func TestDiskCache(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "gist://" + owner + "/" + gistID(t, putter) + "/name.txt"
+
+	recorded := &gistRequests{}
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v3/gists") && !strings.HasPrefix(r.URL.Path, "/gist-raw/") {
+			handler.ServeHTTP(rw, r)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+		handler.ServeHTTP(rec, r)
+		request := r.Method + " " + r.URL.Path
+		if r.Header.Get("If-None-Match") != "" {
+			request += " If-None-Match"
+		}
+		recorded.mut.Lock()
+		recorded.requests = append(recorded.requests, request+" "+http.StatusText(rec.status))
+		recorded.mut.Unlock()
+	})
+
+	dir := t.TempDir()
+	get := func(t *testing.T, putter *putingh.PutInGH, want string) []string {
+		t.Helper()
+		got, err := putter.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		return recorded.take()
+	}
+
+	cached := newPutter(t, srv, putingh.WithDiskCache(dir, time.Hour))
+	t.Run("miss", func(t *testing.T) {
+		if requests := get(t, cached, "v1"); len(requests) == 0 {
+			t.Fatal("a miss made no requests")
+		}
+	})
+	t.Run("hit", func(t *testing.T) {
+		if requests := get(t, cached, "v1"); len(requests) != 0 {
+			t.Fatalf("a hit within the ttl made requests %v", requests)
+		}
+	})
+
+	expired := newPutter(t, srv, putingh.WithDiskCache(dir, 0))
+	t.Run("not modified", func(t *testing.T) {
+		requests := get(t, expired, "v1")
+		if len(requests) != 1 || !strings.HasSuffix(requests[0], "If-None-Match Not Modified") {
+			t.Fatalf("got requests %v, want a single revalidation", requests)
+		}
+	})
+	t.Run("modified", func(t *testing.T) {
+		_, err := putter.PutIn(ctx, uri, strings.NewReader("v2"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded.take()
+		requests := get(t, expired, "v2")
+		if len(requests) == 0 || !strings.HasSuffix(requests[0], "If-None-Match OK") {
+			t.Fatalf("got requests %v, want the revalidation to fail", requests)
+		}
+		// the refetched content replaces the entry
+		if requests := get(t, cached, "v2"); len(requests) != 0 {
+			t.Fatalf("a hit within the ttl made requests %v", requests)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package putingh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// checksumSuffix names the sidecar asset holding the SHA-256 of an asset, in sha256sum format.
+const checksumSuffix = ".sha256"
+
+// WithVerifyAssetChecksum verifies assets read by GetFromReleasesAsset against their <name>.sha256 sidecar
+// in the same release, the read fails with ErrChecksumMismatch at EOF on a different digest.
+// Assets without a sidecar are not verified.
+func WithVerifyAssetChecksum(verify bool) Option {
+	return func(p *PutInGH) {
+		p.verifyAssetChecksum = verify
+	}
+}
+
+// assetChecksum returns the digest in the sidecar of asset, an empty digest when there is none.
+func (s *PutInGH) assetChecksum(ctx context.Context, assets []*ghv3.ReleaseAsset, asset *ghv3.ReleaseAsset) (string, error) {
+	var sidecar *ghv3.ReleaseAsset
+	for _, a := range assets {
+		if a.GetName() == asset.GetName()+checksumSuffix {
+			sidecar = a
+			break
+		}
+	}
+	if sidecar == nil || sidecar.BrowserDownloadURL == nil {
+		return "", nil
+	}
+	resp, err := s.httpGet(ctx, *sidecar.BrowserDownloadURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%w: empty %s", ErrChecksumMismatch, sidecar.GetName())
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// checksumReader returns ErrChecksumMismatch instead of io.EOF when the content read does not hash to want.
+type checksumReader struct {
+	r    io.Reader
+	name string
+	want string
+	hash hash.Hash
+}
+
+func newChecksumReader(r io.Reader, name, want string) io.Reader {
+	return &checksumReader{
+		r:    r,
+		name: name,
+		want: want,
+		hash: sha256.New(),
+	}
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+	if err == io.EOF {
+		if got := hex.EncodeToString(c.hash.Sum(nil)); got != c.want {
+			return n, fmt.Errorf("%w: %s is %s, expected %s", ErrChecksumMismatch, c.name, got, c.want)
+		}
+	}
+	return n, err
+}
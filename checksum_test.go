@@ -0,0 +1,61 @@
+package putingh_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestVerifyAssetChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("content"))
+	digest := hex.EncodeToString(sum[:])
+	for name, tc := range map[string]struct {
+		sidecar string
+		verify  bool
+		wantErr error
+	}{
+		"matching":              {sidecar: digest + "  name.txt\n", verify: true},
+		"matching upper case":   {sidecar: strings.ToUpper(digest) + "\n", verify: true},
+		"mismatching":           {sidecar: strings.Repeat("0", len(digest)) + "  name.txt\n", verify: true, wantErr: putingh.ErrChecksumMismatch},
+		"empty":                 {sidecar: "\n", verify: true, wantErr: putingh.ErrChecksumMismatch},
+		"no sidecar":            {verify: true},
+		"mismatching unchecked": {sidecar: strings.Repeat("0", len(digest)) + "\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv, putter := putinghtest.NewServer(t)
+			ctx := context.Background()
+			uri := "asset://" + owner + "/repo/v1/name.txt"
+			_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.sidecar != "" {
+				_, err = putter.PutIn(ctx, uri+".sha256", strings.NewReader(tc.sidecar))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reader := newPutter(t, srv, putingh.WithVerifyAssetChecksum(tc.verify))
+			got, err := reader.GetBytes(ctx, uri)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("got %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != "content" {
+				t.Fatalf("got %q, want %q", got, "content")
+			}
+		})
+	}
+}
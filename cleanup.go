@@ -0,0 +1,40 @@
+package putingh
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cleanupGit removes what a cancelled fetch or push leaves in the worktree dir, a stale index.lock
+// and the temp files of packs, objects and packed refs, so the next call can open it again.
+// A worktree that still fails to open is re-initialized with WithAutoRepairWorktree.
+func (s *PutInGH) cleanupGit(dir string) {
+	dot := filepath.Join(dir, ".git")
+	os.Remove(filepath.Join(dot, "index.lock"))
+	removeGlob(filepath.Join(dot, "._packed-refs*"))
+
+	pack := filepath.Join(dot, "objects", "pack")
+	removeGlob(filepath.Join(pack, "tmp_pack_*"))
+	removeGlob(filepath.Join(pack, "tmp_obj_*"))
+	// an index whose pack was never renamed into place
+	idxs, _ := filepath.Glob(filepath.Join(pack, "pack-*.idx"))
+	for _, idx := range idxs {
+		_, err := os.Stat(strings.TrimSuffix(idx, ".idx") + ".pack")
+		if os.IsNotExist(err) {
+			os.Remove(idx)
+		}
+	}
+
+	_, err := plainOpenGit(dir)
+	if err != nil && s.autoRepairWorktree {
+		s.repairGit(dir, err)
+	}
+}
+
+func removeGlob(pattern string) {
+	names, _ := filepath.Glob(pattern)
+	for _, name := range names {
+		os.Remove(name)
+	}
+}
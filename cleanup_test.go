@@ -0,0 +1,70 @@
+package putingh_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestCancelledFetchLeavesWorktreeReopenable(t *testing.T) {
+	tmp := t.TempDir()
+	srv, putter := putinghtest.NewServer(t, putingh.WithTmpDir(tmp))
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "v1"})
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/README.md"
+	_, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "v2", "big.bin": strings.Repeat("x", 1<<20)})
+
+	dot := filepath.Join(tmp, "git", owner, "repo", "main", ".git")
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/git-upload-pack") || cancelCtx.Err() != nil {
+			handler.ServeHTTP(rw, r)
+			return
+		}
+		// send half of the pack, then hang until the fetch is cancelled
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		for k, v := range rec.Header() {
+			rw.Header()[k] = v
+		}
+		body := rec.Body.Bytes()
+		rw.Write(body[:len(body)/2])
+		rw.(http.Flusher).Flush()
+		// as a writer that was interrupted would leave it
+		os.WriteFile(filepath.Join(dot, "index.lock"), nil, 0o644)
+		cancel()
+		<-r.Context().Done()
+	})
+
+	_, err = putter.GetBytes(cancelCtx, uri)
+	if err == nil {
+		t.Fatal("the cancelled fetch succeeded")
+	}
+	for _, pattern := range []string{"index.lock", "objects/pack/tmp_*"} {
+		names, _ := filepath.Glob(filepath.Join(dot, pattern))
+		if len(names) != 0 {
+			t.Fatalf("left %q behind", names)
+		}
+	}
+
+	got, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "v2" {
+		t.Fatalf("got %q, want %q", got, "v2")
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/wzshiming/putingh"
@@ -16,26 +18,57 @@ Usage:
 	# Put file in git repository
 	GH_TOKEN=you_github_token putingh git://owner/repository/branch/name[/name]... localfile
 	
+	# Put file in git repository with a commit message and author
+	GH_TOKEN=you_github_token putingh -m "Update name" -author name -email name@example.com git://owner/repository/branch/name[/name]... localfile
+	
 	# Put file in git repository release assets
 	GH_TOKEN=you_github_token putingh asset://owner/repository/release/name localfile
 	
+	# Put release notes
+	GH_TOKEN=you_github_token putingh releasenotes://owner/repository/release localfile
+	
 	# Put file in gist
 	GH_TOKEN=you_github_token putingh gist://owner/gist_id/name localfile
 	
 	# Get file from git repository
 	GH_TOKEN=you_github_token putingh git://owner/repository/branch/name[/name]...
 	
+	# Get file from a pull request head
+	GH_TOKEN=you_github_token putingh git://owner/repository/pull/number/name[/name]...
+	
 	# Get file from git repository release assets
 	GH_TOKEN=you_github_token putingh asset://owner/repository/release/name
 	
+	# Get release notes
+	GH_TOKEN=you_github_token putingh releasenotes://owner/repository/release
+	
+	# Get repository archive
+	GH_TOKEN=you_github_token putingh archive://owner/repository/ref?format=tarball
+	
 	# Get file from gist
 	GH_TOKEN=you_github_token putingh gist://owner/gist_id/name
+	
+	# Get file from gist by its URL
+	GH_TOKEN=you_github_token putingh https://gist.github.com/owner/gist_id/name
+
+Flags:
 `
 
+var (
+	message = flag.String("m", "", "commit message of a put to a git repository")
+	author  = flag.String("author", "", "commit author name of a put to a git repository")
+	email   = flag.String("email", "", "commit author email of a put to a git repository")
+)
+
 func main() {
-	args := os.Args[1:]
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
 	if len(args) == 0 || len(args) > 2 {
-		fmt.Fprint(os.Stderr, usage)
+		flag.Usage()
 		return
 	}
 	token, ok := os.LookupEnv("GH_TOKEN")
@@ -49,14 +82,34 @@ func main() {
 		if err != nil {
 			log.Printf("warning: parse error: TIMEOUT=%s: %s", timeout, err)
 		} else {
-			ctx, _ = context.WithTimeout(ctx, d)
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
 		}
 	}
 	var options []putingh.Option
 	if v, ok := os.LookupEnv("TMP_DIR"); ok {
 		options = append(options, putingh.WithTmpDir(v))
 	}
+	if *message != "" {
+		options = append(options, putingh.WithGitCommitMessage(func(owner, repo, branch, name, path string) string {
+			return *message
+		}))
+	}
+	if *author != "" || *email != "" {
+		name := *author
+		if name == "" {
+			name = "bot"
+		}
+		options = append(options, putingh.WithGitAuthorSignature(name, *email))
+	}
 	putter := putingh.NewPutInGH(token, options...)
+	if v := os.Getenv("CHECK_SCOPES"); v != "" {
+		err := putter.CheckScopes(ctx, strings.Split(v, ",")...)
+		if err != nil {
+			log.Fatalf("check scopes: %s", err)
+		}
+	}
 
 	if len(args) == 2 {
 		url, err := putter.PutInWithFile(ctx, args[0], args[1])
@@ -0,0 +1,34 @@
+package putingh_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGitCommitMessageParts(t *testing.T) {
+	for want, opt := range map[string]putingh.Option{
+		"Update name.txt\n\nWritten by putingh\nto main": putingh.WithGitCommitMessageParts(func(owner, repo, branch, name, path string) (string, string) {
+			return "Update " + name, "Written by putingh\nto " + branch
+		}),
+		// without a body there is no blank line
+		"Update name.txt": putingh.WithGitCommitMessageParts(func(owner, repo, branch, name, path string) (string, string) {
+			return "Update " + name, ""
+		}),
+		"Single string": putingh.WithGitCommitMessage(func(owner, repo, branch, name, path string) string {
+			return "Single string"
+		}),
+	} {
+		srv, putter := putinghtest.NewServer(t, opt)
+		_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", strings.NewReader("content"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit := headCommit(t, srv, "repo", "main"); commit.Message != want {
+			t.Fatalf("got message %q, want %q", commit.Message, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithGitContentsAPI makes git puts commit through the GitHub Contents API instead of a local clone,
+// the commits are created and verified by GitHub.
+func WithGitContentsAPI(enable bool) Option {
+	return func(p *PutInGH) {
+		p.gitContentsAPI = enable
+	}
+}
+
+// WithCommitBranch sets the base branch used to create the target branch
+// when it does not exist yet and commits go through the Contents API.
+func WithCommitBranch(branch string) Option {
+	return func(p *PutInGH) {
+		p.commitBranch = branch
+	}
+}
+
+func (s *PutInGH) putInGitContents(ctx context.Context, owner, repo, branch, name string, r io.Reader) (string, plumbing.Hash, error) {
+	if isPullRef(branch) {
+		return "", plumbing.ZeroHash, fmt.Errorf("%s is read-only", gitRemoteRef(branch))
+	}
+	err := s.checkRepoVisibility(ctx, owner, repo)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	data, err := io.ReadAll(s.limitReader(r))
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	if s.commitBranch != "" && s.commitBranch != branch {
+		err = s.ensureRemoteBranch(ctx, owner, repo, branch, s.commitBranch)
+		if err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+	}
+
+	path := s.gitURL(owner, repo) + "/" + name
+	opt := s.gitCommitOption(owner, repo, branch, name, path)
+	message := s.commitMessage(owner, repo, branch, name, path)
+	fileOpt := &ghv3.RepositoryContentFileOptions{
+		Message: &message,
+		Content: data,
+		Branch:  &branch,
+	}
+	if opt != nil && opt.Author != nil && opt.Author.Name != "" && opt.Author.Email != "" {
+		fileOpt.Author = &ghv3.CommitAuthor{
+			Name:  &opt.Author.Name,
+			Email: &opt.Author.Email,
+		}
+	}
+
+	var hash plumbing.Hash
+	blob := plumbing.ComputeHash(plumbing.BlobObject, data).String()
+	for retried := false; ; retried = true {
+		sha, err := s.contentsSHA(ctx, owner, repo, branch, name)
+		if err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+		if sha == blob {
+			if s.requireChange {
+				return "", plumbing.ZeroHash, fmt.Errorf("%w: %s", ErrNoChange, name)
+			}
+			// the tip of branch is not known without another request
+			break
+		}
+
+		var resp *ghv3.RepositoryContentResponse
+		var response *ghv3.Response
+		if sha == "" {
+			fileOpt.SHA = nil
+			resp, response, err = s.cliv3.Repositories.CreateFile(ctx, owner, repo, escapePath(name), fileOpt)
+		} else {
+			fileOpt.SHA = &sha
+			resp, response, err = s.cliv3.Repositories.UpdateFile(ctx, owner, repo, escapePath(name), fileOpt)
+		}
+		if err != nil {
+			if !retried && response != nil && response.StatusCode == http.StatusConflict {
+				continue
+			}
+			return "", plumbing.ZeroHash, err
+		}
+		hash = plumbing.NewHash(resp.Commit.GetSHA())
+		break
+	}
+	return s.gitURL(owner, repo) + "/raw/" + branch + "/" + name, hash, nil
+}
+
+// contentsSHA returns the blob SHA of name on branch, or empty if it does not exist.
+func (s *PutInGH) contentsSHA(ctx context.Context, owner, repo, branch, name string) (string, error) {
+	file, _, response, err := s.cliv3.Repositories.GetContents(ctx, owner, repo, name, &ghv3.RepositoryContentGetOptions{
+		Ref: branch,
+	})
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	if file == nil {
+		return "", fmt.Errorf("%q is a directory", name)
+	}
+	return file.GetSHA(), nil
+}
+
+// ensureRemoteBranch creates branch from the head of base if it does not exist.
+func (s *PutInGH) ensureRemoteBranch(ctx context.Context, owner, repo, branch, base string) error {
+	_, response, err := s.cliv3.Git.GetRef(ctx, owner, repo, "heads/"+branch)
+	if err == nil {
+		return nil
+	}
+	if response == nil || response.StatusCode != http.StatusNotFound {
+		return err
+	}
+	baseRef, _, err := s.cliv3.Git.GetRef(ctx, owner, repo, "heads/"+base)
+	if err != nil {
+		return fmt.Errorf("get base branch %s: %w", base, err)
+	}
+	_, _, err = s.cliv3.Git.CreateRef(ctx, owner, repo, &ghv3.Reference{
+		Ref:    ghv3.String("refs/heads/" + branch),
+		Object: baseRef.Object,
+	})
+	if err != nil {
+		return fmt.Errorf("create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// escapePath escapes each segment of name, go-github puts the path of a created or updated file into the URL as is.
+func escapePath(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
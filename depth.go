@@ -0,0 +1,9 @@
+package putingh
+
+// WithGitFetchDepth fetches only the last depth commits of a branch into the worktree instead of its whole history,
+// 1 is enough to read and write the latest files. Pushes work from the shallow history.
+func WithGitFetchDepth(depth int) Option {
+	return func(p *PutInGH) {
+		p.gitFetchDepth = depth
+	}
+}
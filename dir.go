@@ -0,0 +1,140 @@
+package putingh
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// WithSkipUnchanged makes PutInGitDir leave worktree files alone whose blob already matches the local file,
+// so running it again after a failed push only writes what changed.
+func WithSkipUnchanged(skip bool) Option {
+	return func(p *PutInGH) {
+		p.skipUnchanged = skip
+	}
+}
+
+// PutInGitDir writes the regular files below localDir to prefix on branch in a single commit
+// and returns the raw URL of each file.
+func (s *PutInGH) PutInGitDir(ctx context.Context, owner, repo, branch, prefix, localDir string) ([]string, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	var manifest []manifestEntry
+	err = filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+		if s.skipUnchanged {
+			unchanged, err := s.isGitFileUnchanged(dir, name, p)
+			if err != nil {
+				return err
+			}
+			if unchanged {
+				if s.manifestWriter != nil {
+					entry, err := manifestEntryOfFile(name, p)
+					if err != nil {
+						return err
+					}
+					manifest = append(manifest, entry)
+				}
+				names = append(names, name)
+				return nil
+			}
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		var r io.Reader = f
+		var hr *hashReader
+		if s.manifestWriter != nil {
+			hr = newManifestReader(f)
+			r = hr
+		}
+		_, err = s.writeGitFile(dir, name, r)
+		if err != nil {
+			return err
+		}
+		if hr != nil {
+			manifest = append(manifest, hr.manifestEntry(name))
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return names, nil
+	}
+
+	message := prefix
+	if message == "" {
+		message = filepath.Base(localDir)
+	}
+	_, _, err = s.commitGit(ctx, repository, owner, repo, branch, message, dir, names)
+	if err != nil {
+		return nil, err
+	}
+	if s.manifestWriter != nil {
+		err = s.writeManifest(manifest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	urls := make([]string, 0, len(names))
+	for _, name := range names {
+		urls = append(urls, s.gitURL(owner, repo)+"/raw/"+branch+"/"+name)
+	}
+	return urls, nil
+}
+
+// isGitFileUnchanged reports whether the worktree file name has the same blob as the local file.
+func (s *PutInGH) isGitFileUnchanged(dir, name, local string) (bool, error) {
+	fname, err := safeJoin(dir, name)
+	if err != nil {
+		return false, err
+	}
+	current, err := blobHashOfFile(fname)
+	if err != nil || current == "" {
+		return false, err
+	}
+	want, err := blobHashOfFile(local)
+	if err != nil {
+		return false, err
+	}
+	return current == want, nil
+}
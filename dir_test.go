@@ -0,0 +1,145 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInGitDirSkipUnchanged(t *testing.T) {
+	tmp := t.TempDir()
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+
+	local := t.TempDir()
+	files := map[string]string{
+		"a.txt":     "a",
+		"sub/b.txt": "b",
+		"sub/c.txt": "c",
+	}
+	for name, content := range files {
+		fname := filepath.Join(local, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(fname), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(fname, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	putter := newPutter(t, srv, putingh.WithTmpDir(tmp), putingh.WithSkipUnchanged(true))
+	ctx := context.Background()
+	_, err := putter.PutInGitDir(ctx, owner, "repo", "main", "docs", local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := filepath.Join(tmp, "git", owner, "repo", "main", "docs")
+	written := map[string]os.FileInfo{}
+	for name := range files {
+		fi, err := os.Stat(filepath.Join(worktree, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		written[name] = fi
+	}
+
+	// one file changes before the second run
+	err = os.WriteFile(filepath.Join(local, "sub", "c.txt"), []byte("c changed"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["sub/c.txt"] = "c changed"
+	urls, err := putter.PutInGitDir(ctx, owner, "repo", "main", "docs", local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != len(files) {
+		t.Fatalf("got urls %v, want one for each of %d files", urls, len(files))
+	}
+	for name, before := range written {
+		after, err := os.Stat(filepath.Join(worktree, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rewritten := !os.SameFile(before, after)
+		if rewritten != (name == "sub/c.txt") {
+			t.Errorf("%s: rewritten is %v", name, rewritten)
+		}
+	}
+
+	tree, err := headCommit(t, srv, "repo", "main").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		file, err := tree.File("docs/" + name)
+		if err != nil {
+			t.Fatalf("docs/%s was not pushed: %v", name, err)
+		}
+		got, err := file.Contents()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("docs/%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPutInGitDirManifest(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	local := t.TempDir()
+	files := map[string]string{
+		"a.txt":     "a",
+		"sub/b.txt": "bb",
+	}
+	for name, content := range files {
+		fname := filepath.Join(local, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(fname), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(fname, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var manifest bytes.Buffer
+	putter := newPutter(t, srv, putingh.WithManifestWriter(&manifest), putingh.WithSkipUnchanged(true))
+	ctx := context.Background()
+	want := map[string]bool{}
+	for name, content := range files {
+		sum := sha256.Sum256([]byte(content))
+		want["docs/"+name+"  "+hex.EncodeToString(sum[:])+"  "+strconv.Itoa(len(content))] = true
+	}
+	// the files skipped as unchanged on the second run are listed too
+	for run := 1; run <= 2; run++ {
+		manifest.Reset()
+		_, err := putter.PutInGitDir(ctx, owner, "repo", "main", "docs", local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(manifest.String(), "\n"), "\n")
+		if len(lines) != len(want) {
+			t.Fatalf("run %d: got manifest %q, want %d lines", run, manifest.String(), len(want))
+		}
+		for _, line := range lines {
+			if !want[line] {
+				t.Fatalf("run %d: unexpected manifest line %q", run, line)
+			}
+		}
+	}
+}
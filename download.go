@@ -0,0 +1,116 @@
+package putingh
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// newDownloadClient returns the client for raw downloads, it only authenticates
+// requests to GitHub hosts so the token does not follow redirects elsewhere.
+func (s *PutInGH) newDownloadClient(src oauth2.TokenSource) *http.Client {
+	var auth, base http.RoundTripper
+	switch t := s.httpCli.Transport.(type) {
+	case *oauth2.Transport:
+		base = t.Base
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		if s.skipTLSForRawDownloads {
+			base = insecureTransport(base)
+		}
+		auth = s.withTokenTransport(&oauth2.Transport{
+			Source: src,
+			Base:   base,
+		})
+	default:
+		// a custom transport manages the credentials itself, it is used for every host
+		base = t
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		if s.skipTLSForRawDownloads {
+			base = insecureTransport(base)
+		}
+		auth = base
+	}
+	cli := *s.httpCli
+	cli.Transport = &trustedAuthTransport{
+		trusted: s.isTrustedHost,
+		auth:    auth,
+		base:    base,
+	}
+	return &cli
+}
+
+// insecureTransport returns rt skipping TLS verification,
+// a transport that is not an *http.Transport is returned as is.
+func insecureTransport(rt http.RoundTripper) http.RoundTripper {
+	switch t := rt.(type) {
+	case *stripAuthTransport:
+		return &stripAuthTransport{base: insecureTransport(t.base)}
+	case *http.Transport:
+		tr := t.Clone()
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.InsecureSkipVerify = true
+		return tr
+	}
+	return rt
+}
+
+// isTrustedHost reports whether host may receive the token.
+func (s *PutInGH) isTrustedHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "github.com" ||
+		strings.HasSuffix(host, ".github.com") ||
+		strings.HasSuffix(host, ".githubusercontent.com") {
+		return true
+	}
+	for _, uri := range []string{s.host, s.apiURL, s.uploadURL} {
+		if uri == "" {
+			continue
+		}
+		u, err := url.Parse(uri)
+		if err == nil && strings.EqualFold(u.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+type trustedAuthTransport struct {
+	trusted func(host string) bool
+	auth    http.RoundTripper
+	base    http.RoundTripper
+}
+
+func (t *trustedAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.trusted(req.URL.Hostname()) {
+		return t.auth.RoundTrip(req)
+	}
+	req = req.Clone(context.WithValue(req.Context(), untrustedHostContextKey{}, true))
+	req.Header.Del("Authorization")
+	return t.base.RoundTrip(req)
+}
+
+type untrustedHostContextKey struct{}
+
+// stripAuthTransport drops the Authorization header of requests trustedAuthTransport sends to other hosts,
+// after any transport wrapped around it added one.
+type stripAuthTransport struct {
+	base http.RoundTripper
+}
+
+func (t *stripAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Value(untrustedHostContextKey{}) != nil && req.Header.Get("Authorization") != "" {
+		req = req.Clone(req.Context())
+		req.Header.Del("Authorization")
+	}
+	return t.base.RoundTrip(req)
+}
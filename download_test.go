@@ -0,0 +1,109 @@
+package putingh_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// countingTransport counts the raw gist downloads it sends.
+type countingTransport struct {
+	base http.RoundTripper
+	n    atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.URL.Path, "/gist-raw/") {
+		c.n.Add(1)
+	}
+	return c.base.RoundTrip(req)
+}
+
+func TestRawDownloadTokenStaysOnGitHub(t *testing.T) {
+	custom := &countingTransport{}
+	withCustom := putingh.WithHTTPClient(func(cli *http.Client) *http.Client {
+		c := *cli
+		custom.base = cli.Transport
+		c.Transport = custom
+		return &c
+	})
+	tests := map[string][]putingh.Option{
+		"default":          nil,
+		"custom":           {withCustom},
+		"custom skip tls":  {withCustom, putingh.WithSkipTLSForRawDownloads(true)},
+		"default skip tls": {putingh.WithSkipTLSForRawDownloads(true)},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			custom.n.Store(0)
+			testRawDownloadToken(t, opts...)
+			if strings.HasPrefix(name, "custom") && custom.n.Load() == 0 {
+				t.Fatal("the raw downloads did not use the transport set with WithHTTPClient")
+			}
+		})
+	}
+}
+
+func testRawDownloadToken(t *testing.T, opts ...putingh.Option) {
+	// another host, the fake GitHub listens on 127.0.0.1
+	var leaked atomic.Value
+	leaked.Store("")
+	other := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		leaked.Store(r.Header.Get("Authorization"))
+		rw.Write([]byte("elsewhere"))
+	}))
+	t.Cleanup(other.Close)
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/private.txt", strings.NewReader("private"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.PutIn(ctx, "gist://"+owner+"/*/moved.txt", strings.NewReader("moved"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/gist-raw/") {
+			// a private gist is only served with the token
+			if r.Header.Get("Authorization") == "" {
+				http.NotFound(rw, r)
+				return
+			}
+			if strings.HasSuffix(r.URL.Path, "/moved.txt") {
+				http.Redirect(rw, r, otherURL+"/moved.txt", http.StatusFound)
+				return
+			}
+		}
+		handler.ServeHTTP(rw, r)
+	})
+
+	reader := newPutter(t, srv, append(opts, putingh.WithGistStreamThreshold(1))...)
+	got, err := reader.GetBytes(ctx, "gist://"+owner+"/*/private.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "private" {
+		t.Fatalf("got %q, want %q", got, "private")
+	}
+	got, err = reader.GetBytes(ctx, "gist://"+owner+"/*/moved.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "elsewhere" {
+		t.Fatalf("got %q, want the redirected content", got)
+	}
+	if auth := leaked.Load().(string); auth != "" {
+		t.Fatalf("the redirect to another host got the token %q", auth)
+	}
+}
@@ -0,0 +1,112 @@
+package putingh
+
+import (
+	"io"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// EventOp is the kind of operation an Event describes.
+type EventOp string
+
+const (
+	EventGet EventOp = "get"
+	EventPut EventOp = "put"
+)
+
+// Event describes one finished GetFrom, PutIn or PutInWithFile.
+type Event struct {
+	Op     EventOp
+	Scheme string
+	Target string
+	// Bytes is what was read, from the source of a put or by the caller of a get.
+	Bytes    int64
+	Duration time.Duration
+	// Err is the outcome, nil on success.
+	Err error
+}
+
+// WithEventChannel sends an Event to ch for every operation, a get is sent when its reader
+// hits EOF, fails or is closed. Events are dropped instead of blocking when ch is full,
+// see DroppedEvents.
+func WithEventChannel(ch chan<- Event) Option {
+	return func(p *PutInGH) {
+		p.events = ch
+	}
+}
+
+// DroppedEvents returns the number of events dropped because the channel was full.
+func (s *PutInGH) DroppedEvents() uint64 {
+	return s.droppedEvents.Load()
+}
+
+func (s *PutInGH) emitEvent(op EventOp, uri string, n int64, start time.Time, err error) {
+	ev := Event{
+		Op:       op,
+		Target:   uri,
+		Bytes:    n,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if u, err := url.Parse(uri); err == nil {
+		ev.Scheme = u.Scheme
+	}
+	select {
+	case s.events <- ev:
+	default:
+		s.droppedEvents.Add(1)
+	}
+}
+
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newEventReader emits the get event of uri once r is done.
+func (s *PutInGH) newEventReader(r io.Reader, uri string, start time.Time) io.Reader {
+	e := &eventReader{
+		countReader: countReader{r: r},
+		s:           s,
+		uri:         uri,
+		start:       start,
+	}
+	return &readCloser{
+		Reader: e,
+		closers: []io.Closer{closerFunc(func() error {
+			e.done(nil)
+			return closeReader(r)
+		})},
+	}
+}
+
+type eventReader struct {
+	countReader
+	s     *PutInGH
+	uri   string
+	start time.Time
+	once  sync.Once
+}
+
+func (e *eventReader) Read(p []byte) (int, error) {
+	n, err := e.countReader.Read(p)
+	if err == io.EOF {
+		e.done(nil)
+	} else if err != nil {
+		e.done(err)
+	}
+	return n, err
+}
+
+func (e *eventReader) done(err error) {
+	e.once.Do(func() {
+		e.s.emitEvent(EventGet, e.uri, e.n, e.start, err)
+	})
+}
@@ -0,0 +1,107 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// nextEvent returns the event waiting in ch.
+func nextEvent(t *testing.T, ch <-chan putingh.Event) putingh.Event {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	default:
+		t.Fatal("no event was sent")
+		return putingh.Event{}
+	}
+}
+
+func TestEventChannel(t *testing.T) {
+	ch := make(chan putingh.Event, 4)
+	_, putter := putinghtest.NewServer(t, putingh.WithEventChannel(ch))
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := nextEvent(t, ch)
+	if ev.Op != putingh.EventPut || ev.Scheme != "git" || ev.Target != uri || ev.Bytes != 7 || ev.Err != nil {
+		t.Fatalf("got put event %+v", ev)
+	}
+
+	_, err = putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev = nextEvent(t, ch)
+	if ev.Op != putingh.EventGet || ev.Scheme != "git" || ev.Target != uri || ev.Bytes != 7 || ev.Err != nil {
+		t.Fatalf("got get event %+v", ev)
+	}
+}
+
+func TestEventChannelFull(t *testing.T) {
+	ch := make(chan putingh.Event)
+	_, putter := putinghtest.NewServer(t, putingh.WithEventChannel(ch))
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := putter.DroppedEvents(); got != 2 {
+		t.Fatalf("got %d dropped events, want 2", got)
+	}
+}
+
+func TestEventChannelAssetStreams(t *testing.T) {
+	var out bytes.Buffer
+	ch := make(chan putingh.Event, 1)
+	_, putter := putinghtest.NewServer(t, putingh.WithEventChannel(ch), putingh.WithOutput(&out))
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	_, err := putter.PutIn(context.Background(), "asset://"+owner+"/repo/v1/name.bin", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "from the reader") {
+		t.Fatalf("asset was not uploaded straight from the reader: %q", out.String())
+	}
+	if ev := nextEvent(t, ch); ev.Bytes != int64(len(data)) {
+		t.Fatalf("got %d bytes, want %d", ev.Bytes, len(data))
+	}
+}
+
+func TestEventChannelPutBytes(t *testing.T) {
+	ch := make(chan putingh.Event, 2)
+	_, putter := putinghtest.NewServer(t, putingh.WithEventChannel(ch), putingh.WithAssetBufferSize(1<<20))
+	ctx := context.Background()
+	uri := "asset://" + owner + "/repo/v1/name.txt"
+	_, err := putter.PutBytes(ctx, uri, []byte("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := nextEvent(t, ch)
+	if ev.Op != putingh.EventPut || ev.Target != uri || ev.Bytes != 7 || ev.Err != nil {
+		t.Fatalf("got put event %+v", ev)
+	}
+
+	bad := "asset://" + owner + "/repo/v1"
+	_, err = putter.PutBytes(ctx, bad, []byte("content"))
+	if err == nil || !strings.Contains(err.Error(), bad) {
+		t.Fatalf("got %v, want an error naming %s", err, bad)
+	}
+	if ev := nextEvent(t, ch); ev.Err == nil {
+		t.Fatalf("got event %+v, want its error", ev)
+	}
+}
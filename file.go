@@ -0,0 +1,127 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// GetFileFromGit copies name of branch to a temp file and returns it open for random access, the caller closes it.
+// The copy is taken while the worktree is locked, so later gets and puts on the branch do not change it.
+func (s *PutInGH) GetFileFromGit(ctx context.Context, owner, repo, branch, name string) (*os.File, error) {
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	f, err := s.spoolGitWorktreeFile(ctx, owner, repo, branch, name)
+	if err != nil || f != nil {
+		return f, err
+	}
+	r, err := s.GetFromGit(ctx, owner, repo, branch, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	return s.spoolFile(r, "git-*")
+}
+
+// GetFileFromReleasesAsset downloads the asset to a temp file and returns it open for random access,
+// the caller closes it.
+func (s *PutInGH) GetFileFromReleasesAsset(ctx context.Context, owner, repo, release, name string) (*os.File, error) {
+	if err := s.checkAllowed("asset", owner, repo); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	r, err := s.GetFromReleasesAsset(ctx, owner, repo, release, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.spoolFile(r, "asset-*")
+}
+
+// spoolGitWorktreeFile copies the regular worktree file name to a temp file,
+// nil without error when it has to be read through GetFromGit.
+func (s *PutInGH) spoolGitWorktreeFile(ctx context.Context, owner, repo, branch, name string) (*os.File, error) {
+	if s.resolveLFS {
+		return nil, nil
+	}
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	dir, _, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	fname, err := safeJoin(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(fname)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(s.sparseCheckout) != 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if fi.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, nil
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	real, err := filepath.EvalSymlinks(fname)
+	if err != nil {
+		return nil, err
+	}
+	if !withinDir(root, real) {
+		return nil, fmt.Errorf("%w: %q resolves outside of the worktree", ErrInvalidPath, name)
+	}
+	f, err := os.Open(real)
+	if err != nil {
+		return nil, err
+	}
+	return s.spoolFile(f, "git-*")
+}
+
+// spoolFile copies r to a temp file and returns it rewound, the name is removed right away where the OS allows it.
+func (s *PutInGH) spoolFile(r io.Reader, pattern string) (*os.File, error) {
+	defer closeReader(r)
+	err := os.MkdirAll(s.tmpDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(s.tmpDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
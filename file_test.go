@@ -0,0 +1,92 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGetFileFromGitSeek(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("0123456789"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := putter.GetFileFromGit(ctx, owner, "repo", "main", "name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// a later put on the branch must not change the open file
+	_, err = putter.PutIn(ctx, uri, strings.NewReader("abcdefghij"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.Seek(6, io.SeekStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(f, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "6789" {
+		t.Fatalf("got %q after seeking, want %q", buf, "6789")
+	}
+	_, err = f.ReadAt(buf[:2], 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:2]) != "12" {
+		t.Fatalf("got %q at 1, want %q", buf[:2], "12")
+	}
+}
+
+func TestGetFileFromGitNotFound(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/dir/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"missing.txt", "dir"} {
+		_, err = putter.GetFileFromGit(ctx, owner, "repo", "main", name)
+		if !errors.Is(err, putingh.ErrNotFound) {
+			t.Errorf("%s: got %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestPutInGitKeepsExecutableMode(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"run.sh": "exec:#!/bin/sh\n",
+	})
+	_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/run.sh", strings.NewReader("#!/bin/sh\necho\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := headCommit(t, srv, "repo", "main").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := tree.FindEntry("run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Mode != filemode.Executable {
+		t.Fatalf("got mode %s, want %s", entry.Mode, filemode.Executable)
+	}
+}
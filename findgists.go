@@ -0,0 +1,31 @@
+package putingh
+
+import (
+	"context"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// FindGists returns the gists of owner accepted by match, or all of them when match is nil.
+// The gists come from the list API, so their files carry no content.
+func (s *PutInGH) FindGists(ctx context.Context, owner string, match func(*ghv3.Gist) bool) ([]*ghv3.Gist, error) {
+	if err := s.checkAllowed("gist", owner, anyFile); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, "gist")
+	defer cancel()
+	gists := []*ghv3.Gist{}
+	err := s.eachGist(ctx, owner, func(list []*ghv3.Gist) bool {
+		for _, gist := range list {
+			if match == nil || match(gist) {
+				gists = append(gists, gist)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gists, nil
+}
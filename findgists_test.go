@@ -0,0 +1,99 @@
+package putingh_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestFindGists(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv,
+		putingh.WithPerPage(2),
+		putingh.WithGistDescription(func(name string) string {
+			return "notes for " + name
+		}),
+	)
+	ctx := context.Background()
+	for _, name := range []string{"a.txt", "b.log", "c.txt", "d.log", "e.txt"} {
+		_, err := putter.PutIn(ctx, "gist://"+owner+"/*/"+name, strings.NewReader("content"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the fake lists every gist at once, page it so matches come from every page
+	var pages int
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/users/"+owner+"/gists" && r.URL.Path != "/api/v3/gists" {
+			handler.ServeHTTP(rw, r)
+			return
+		}
+		pages++
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		var list []*ghv3.Gist
+		json.Unmarshal(rec.Body.Bytes(), &list)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		start, end := (page-1)*perPage, page*perPage
+		if end < len(list) {
+			next := *r.URL
+			q := next.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = q.Encode()
+			rw.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+		} else {
+			end = len(list)
+		}
+		if start > end {
+			start = end
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(list[start:end])
+	})
+
+	descriptions := func(gists []*ghv3.Gist) []string {
+		got := []string{}
+		for _, gist := range gists {
+			got = append(got, gist.GetDescription())
+		}
+		sort.Strings(got)
+		return got
+	}
+	gists, err := putter.FindGists(ctx, owner, func(gist *ghv3.Gist) bool {
+		return strings.HasSuffix(gist.GetDescription(), ".txt")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"notes for a.txt", "notes for c.txt", "notes for e.txt"}
+	if got := descriptions(gists); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if pages != 3 {
+		t.Fatalf("listed %d pages, want 3", pages)
+	}
+
+	gists, err = putter.FindGists(ctx, owner, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gists) != 5 {
+		t.Fatalf("got %d gists without a predicate, want 5", len(gists))
+	}
+}
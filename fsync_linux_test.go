@@ -0,0 +1,41 @@
+//go:build linux
+
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestFsyncTempFiles(t *testing.T) {
+	// syncing /dev/null fails on linux, so the failure tells Sync was called
+	staging := func(name string) (*os.File, func(), error) {
+		f, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+		return f, nil, err
+	}
+	srv, _ := putinghtest.NewServer(t)
+	ctx := context.Background()
+	for _, fsync := range []bool{false, true} {
+		putter := newPutter(t, srv,
+			putingh.WithAssetStagingFunc(staging),
+			putingh.WithFsyncTempFiles(fsync),
+		)
+		// a reader that does not tell its length is staged in a temp file
+		_, err := putter.PutIn(ctx, "asset://"+owner+"/repo/v1/name.bin", struct{ io.Reader }{strings.NewReader("content")})
+		if fsync {
+			if !errors.Is(err, syscall.EINVAL) {
+				t.Fatalf("got %v, want the error of syncing %s", err, os.DevNull)
+			}
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package putingh
+
+import (
+	"errors"
+	"fmt"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/idxfile"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/filesystem/dotgit"
+)
+
+// WithGitMaxCacheObjects packs the cached worktree of a branch once it holds more than n objects,
+// dropping the objects no reference reaches anymore, such as history replaced by a squash or a force push.
+// The reachable history is kept, so n should be well above its size.
+func WithGitMaxCacheObjects(n int) Option {
+	return func(p *PutInGH) {
+		p.gitMaxCacheObjects = n
+	}
+}
+
+// gcGit repacks the objects of repository when there are more than WithGitMaxCacheObjects,
+// a failure is only reported as the fetch or put it follows already succeeded.
+func (s *PutInGH) gcGit(repository *gogit.Repository) {
+	if s.gitMaxCacheObjects <= 0 {
+		return
+	}
+	st, ok := repository.Storer.(*filesystem.Storage)
+	if !ok {
+		return
+	}
+	loose, packs, total, err := countGitObjects(dotgit.New(st.Filesystem()))
+	if err != nil {
+		fmt.Fprintf(s.out, "git gc: %v\n", err)
+		return
+	}
+	// a single pack and nothing loose is as small as it gets
+	if total <= int64(s.gitMaxCacheObjects) || (loose == 0 && packs <= 1) {
+		return
+	}
+	err = repository.RepackObjects(&gogit.RepackConfig{})
+	if err != nil {
+		fmt.Fprintf(s.out, "git gc: %v\n", err)
+		return
+	}
+	// loose objects no reference reaches are not part of the new pack
+	err = repository.Prune(gogit.PruneOptions{
+		Handler: repository.DeleteObject,
+	})
+	if err != nil {
+		fmt.Fprintf(s.out, "git gc: %v\n", err)
+	}
+}
+
+// countGitObjects returns the number of loose objects, of packs and of objects in total.
+func countGitObjects(dot *dotgit.DotGit) (loose, packs int, total int64, err error) {
+	objs, err := dot.Objects()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	hashes, err := dot.ObjectPacks()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	total = int64(len(objs))
+	for _, hash := range hashes {
+		n, err := countPackObjects(dot, hash)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		total += n
+	}
+	return len(objs), len(hashes), total, nil
+}
+
+func countPackObjects(dot *dotgit.DotGit, hash plumbing.Hash) (int64, error) {
+	f, err := dot.ObjectPackIdx(hash)
+	if err != nil {
+		if errors.Is(err, dotgit.ErrPackfileNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+	idx := idxfile.NewMemoryIndex()
+	err = idxfile.NewDecoder(f).Decode(idx)
+	if err != nil {
+		return 0, err
+	}
+	return idx.Count()
+}
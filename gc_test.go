@@ -0,0 +1,73 @@
+package putingh_test
+
+import (
+	"context"
+	"io/fs"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGitMaxCacheObjects(t *testing.T) {
+	sizes := map[bool]int64{}
+	for _, enabled := range []bool{false, true} {
+		tmp := t.TempDir()
+		opts := []putingh.Option{
+			putingh.WithTmpDir(tmp),
+			// each put replaces the history, leaving the earlier blobs unreachable
+			putingh.WithAutoSquash(1),
+		}
+		if enabled {
+			opts = append(opts, putingh.WithGitMaxCacheObjects(16))
+		}
+		_, putter := putinghtest.NewServer(t, opts...)
+		ctx := context.Background()
+		rnd := rand.New(rand.NewSource(1))
+		content := make([]byte, 32<<10)
+		for i := 0; i < 30; i++ {
+			rnd.Read(content)
+			_, err := putter.PutBytes(ctx, "git://"+owner+"/repo/main/name.bin", content)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/name.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(content) {
+			t.Fatal("read back other content than the last put")
+		}
+		sizes[enabled] = dirSize(t, filepath.Join(tmp, "git", owner, "repo", "main", ".git"))
+	}
+	// the random blobs do not compress, unpruned about 30 of them are kept
+	if sizes[true] > 4*32<<10 {
+		t.Fatalf("the cache takes %d bytes, %d without pruning", sizes[true], sizes[false])
+	}
+	if sizes[false] < 20*32<<10 {
+		t.Fatalf("the cache without pruning takes only %d bytes, the test proves nothing", sizes[false])
+	}
+}
+
+func dirSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return size
+}
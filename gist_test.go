@@ -0,0 +1,158 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// BenchmarkGetFromGist compares the bytes allocated reading a large gist file inline and streamed from its raw URL.
+func BenchmarkGetFromGist(b *testing.B) {
+	content := strings.Repeat("0123456789abcdef", 1<<14)
+	for name, threshold := range map[string]int{
+		"inline": 0,
+		"stream": 1 << 10,
+	} {
+		b.Run(name, func(b *testing.B) {
+			srv, putter := putinghtest.NewServer(b)
+			ctx := context.Background()
+			_, err := putter.PutIn(ctx, "gist://"+owner+"/*/large.txt", strings.NewReader(content))
+			if err != nil {
+				b.Fatal(err)
+			}
+			// a gist listing carries no content, get the gist by ID to have it inline
+			uri := "gist://" + owner + "/" + gistID(b, putter) + "/large.txt"
+			reader := newPutter(b, srv, putingh.WithGistStreamThreshold(threshold))
+			b.SetBytes(int64(len(content)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r, err := reader.GetFrom(ctx, uri)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, err = io.Copy(io.Discard, r)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if c, ok := r.(io.Closer); ok {
+					c.Close()
+				}
+			}
+		})
+	}
+}
+
+func TestGistDescription(t *testing.T) {
+	for want, opts := range map[string][]putingh.Option{
+		// the gist id segment is not a description
+		"": nil,
+		"notes for name.txt": {putingh.WithGistDescription(func(name string) string {
+			return "notes for " + name
+		})},
+	} {
+		srv, _ := putinghtest.NewServer(t)
+		putter := newPutter(t, srv, opts...)
+		ctx := context.Background()
+		_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("content"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gists, err := putter.FindGists(ctx, owner, func(*ghv3.Gist) bool { return true })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := gists[0].GetDescription(); got != want {
+			t.Fatalf("got description %q, want %q", got, want)
+		}
+	}
+}
+
+func TestPutInGistKeepsOtherFiles(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutInGistFiles(ctx, owner, "*", map[string]io.Reader{
+		"a.txt": strings.NewReader("a"),
+		"b.txt": strings.NewReader("b"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gistID(t, putter)
+
+	var edited []string
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == "/api/v3/gists/"+id {
+			body, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			var payload struct {
+				Files map[string]json.RawMessage `json:"files"`
+			}
+			json.Unmarshal(body, &payload)
+			for name := range payload.Files {
+				edited = append(edited, name)
+			}
+		}
+		handler.ServeHTTP(rw, r)
+	})
+
+	_, err = putter.PutIn(ctx, "gist://"+owner+"/"+id+"/a.txt", strings.NewReader("a updated"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edited) != 1 || edited[0] != "a.txt" {
+		t.Fatalf("edited files %v, want only a.txt", edited)
+	}
+	for name, want := range map[string]string{
+		"a.txt": "a updated",
+		"b.txt": "b",
+	} {
+		got, err := putter.GetBytes(ctx, "gist://"+owner+"/"+id+"/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGistBinaryEncoding(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithGistBinaryEncoding(true))
+	// reads remove the encoding without the option too
+	reader := newPutter(t, srv)
+	ctx := context.Background()
+	for name, content := range map[string][]byte{
+		"binary.bin": {0x00, 0xff, 0xfe, 'x', 0x80},
+		// base64 text the user stored is returned as it is, not decoded
+		"base64.txt": []byte(base64.StdEncoding.EncodeToString([]byte("not decoded"))),
+		"magic.txt":  []byte("putingh:base64\nbm90IGRlY29kZWQ="),
+		"text.txt":   []byte("plain text\n"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			uri := "gist://" + owner + "/*/" + name
+			_, err := putter.PutBytes(ctx, uri, content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := reader.GetBytes(ctx, uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Fatalf("got %q, want %q", got, content)
+			}
+		})
+	}
+}
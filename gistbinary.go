@@ -0,0 +1,51 @@
+package putingh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"unicode/utf8"
+)
+
+// gistBinaryMagic starts gist content that putingh stored base64 encoded,
+// content that is merely base64 text does not carry it and is returned as is.
+const gistBinaryMagic = "putingh:base64\n"
+
+// WithGistBinaryEncoding stores content that is not valid UTF-8 text in gists base64 encoded
+// behind a marker line, gist reads remove the encoding again.
+func WithGistBinaryEncoding(encode bool) Option {
+	return func(p *PutInGH) {
+		p.gistBinaryEncoding = encode
+	}
+}
+
+// encodeGistContent returns data as gist file content.
+func (s *PutInGH) encodeGistContent(data []byte) string {
+	if !s.gistBinaryEncoding {
+		return string(data)
+	}
+	if utf8.Valid(data) && bytes.IndexByte(data, 0) < 0 && !bytes.HasPrefix(data, []byte(gistBinaryMagic)) {
+		return string(data)
+	}
+	return gistBinaryMagic + base64.StdEncoding.EncodeToString(data)
+}
+
+// decodeGistContent removes the encoding of encodeGistContent when r carries the marker.
+func decodeGistContent(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(gistBinaryMagic))
+	var decoded io.Reader = br
+	if string(magic) == gistBinaryMagic {
+		br.Discard(len(gistBinaryMagic))
+		decoded = base64.NewDecoder(base64.StdEncoding, br)
+	}
+	return &readCloser{
+		Reader: decoded,
+		closers: []io.Closer{
+			closerFunc(func() error {
+				return closeReader(r)
+			}),
+		},
+	}
+}
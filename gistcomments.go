@@ -0,0 +1,69 @@
+package putingh
+
+import (
+	"context"
+	"time"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// GistComment is a comment on a gist.
+type GistComment struct {
+	ID        int64
+	User      string
+	Body      string
+	CreatedAt time.Time
+}
+
+// GetGistComments returns the comments of the gist oldest first, empty when there are none.
+func (s *PutInGH) GetGistComments(ctx context.Context, owner, gistID string) ([]GistComment, error) {
+	if err := s.checkAllowed("gist", owner, gistID); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	gist, err := s.findGist(ctx, owner, gistID)
+	if err != nil {
+		return nil, err
+	}
+	if gist == nil {
+		return nil, ErrNotFound
+	}
+	comments := []GistComment{}
+	opt := ghv3.ListOptions{
+		PerPage: s.perPage,
+	}
+	for {
+		list, resp, err := s.cliv3.Gists.ListComments(ctx, gist.GetID(), &opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range list {
+			comments = append(comments, GistComment{
+				ID:        comment.GetID(),
+				User:      comment.GetUser().GetLogin(),
+				Body:      comment.GetBody(),
+				CreatedAt: comment.GetCreatedAt().Time,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return comments, nil
+}
+
+// AddGistComment adds a comment with body to the gist.
+func (s *PutInGH) AddGistComment(ctx context.Context, gistID, body string) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+	if err := s.checkAllowed("gist", "", gistID); err != nil {
+		return err
+	}
+	ctx = s.operationContext(ctx)
+	_, _, err := s.cliv3.Gists.CreateComment(ctx, gistID, &ghv3.GistComment{
+		Body: &body,
+	})
+	return err
+}
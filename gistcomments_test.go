@@ -0,0 +1,110 @@
+package putingh_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGistComments(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gistID(t, putter)
+
+	comments, err := putter.GetGistComments(ctx, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comments == nil || len(comments) != 0 {
+		t.Fatalf("got %#v, want an empty slice", comments)
+	}
+
+	for _, body := range []string{"first", "second"} {
+		err = putter.AddGistComment(ctx, id, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	comments, err = putter.GetGistComments(ctx, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || comments[0].Body != "first" || comments[1].Body != "second" {
+		t.Fatalf("got %#v, want first and second", comments)
+	}
+	if comments[0].User != putinghtest.Login || comments[0].ID == 0 || comments[0].CreatedAt.IsZero() {
+		t.Fatalf("got %#v, want the user, the ID and the time set", comments[0])
+	}
+
+	_, err = putter.GetGistComments(ctx, owner, "missing")
+	if !errors.Is(err, putingh.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetGistCommentsPaginates(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithPerPage(2))
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gistID(t, putter)
+
+	const total = 5
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/gists/"+id+"/comments" {
+			handler.ServeHTTP(rw, r)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		list := []*ghv3.GistComment{}
+		for i := (page-1)*perPage + 1; i <= page*perPage && i <= total; i++ {
+			list = append(list, &ghv3.GistComment{
+				ID:   ghv3.Int64(int64(i)),
+				Body: ghv3.String("comment " + strconv.Itoa(i)),
+			})
+		}
+		if page*perPage < total {
+			next := *r.URL
+			q := next.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = q.Encode()
+			rw.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(list)
+	})
+
+	comments, err := putter.GetGistComments(ctx, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != total {
+		t.Fatalf("got %d comments, want %d", len(comments), total)
+	}
+	for i, comment := range comments {
+		if comment.ID != int64(i+1) {
+			t.Fatalf("got comment %d at %d, want them in order", comment.ID, i)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package putingh
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// PutInGistFiles writes all contents to the gist in a single request and returns the raw URL of each written file,
+// a nil reader deletes that file. The gist is created when it does not exist.
+func (s *PutInGH) PutInGistFiles(ctx context.Context, owner, gistID string, contents map[string]io.Reader) (map[string]string, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAllowed("gist", owner, gistID); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := map[string]*ghv3.GistFile{}
+	written := []string{}
+	for _, name := range names {
+		r := contents[name]
+		if r == nil {
+			files[name] = nil
+			continue
+		}
+		data, err := io.ReadAll(s.limitReader(r))
+		if err != nil {
+			return nil, err
+		}
+		files[name] = &ghv3.GistFile{
+			Content:  ghv3.String(s.encodeGistContent(data)),
+			Language: s.gistLanguageOf(name),
+		}
+		written = append(written, name)
+	}
+
+	oriGist, err := s.findGist(ctx, owner, gistID, names...)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkGistVisibility(oriGist)
+	if err != nil {
+		return nil, err
+	}
+
+	var gist *ghv3.Gist
+	if oriGist == nil {
+		if len(written) == 0 {
+			return map[string]string{}, nil
+		}
+		create := map[ghv3.GistFilename]ghv3.GistFile{}
+		for _, name := range written {
+			create[ghv3.GistFilename(name)] = *files[name]
+		}
+		gist, _, err = s.cliv3.Gists.Create(ctx, &ghv3.Gist{
+			Public:      ghv3.Bool(true),
+			Files:       create,
+			Description: s.gistDescriptionOf(written[0]),
+		})
+	} else {
+		gist, err = s.editGistFiles(ctx, oriGist.GetID(), files)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make(map[string]string, len(written))
+	for _, name := range written {
+		file := gist.Files[ghv3.GistFilename(name)]
+		raw := file.GetRawURL()
+		urls[name] = strings.SplitN(raw, "/raw/", 2)[0] + "/raw/" + name
+	}
+	return urls, nil
+}
+
+// editGistFiles edits the files of a gist in one request, a nil file is sent as null to delete it,
+// which ghv3.Gist can not express.
+func (s *PutInGH) editGistFiles(ctx context.Context, id string, files map[string]*ghv3.GistFile) (*ghv3.Gist, error) {
+	req, err := s.cliv3.NewRequest(http.MethodPatch, "gists/"+id, map[string]interface{}{
+		"files": files,
+	})
+	if err != nil {
+		return nil, err
+	}
+	gist := &ghv3.Gist{}
+	_, err = s.cliv3.Do(ctx, req, gist)
+	if err != nil {
+		return nil, err
+	}
+	return gist, nil
+}
+
+// GetFromGistAll returns the files of the gist joined in name order,
+// separated by the delimiter set with WithGistJoinDelimiter.
+func (s *PutInGH) GetFromGistAll(ctx context.Context, owner, gistID string) (io.Reader, error) {
+	if err := s.checkAllowed("gist", owner, gistID); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	gist, err := s.findGist(ctx, owner, gistID)
+	if err != nil {
+		return nil, err
+	}
+	if gist == nil {
+		return nil, ErrNotFound
+	}
+	names := make([]string, 0, len(gist.Files))
+	for name := range gist.Files {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	readers := make([]io.Reader, 0, len(names)*2)
+	for i, name := range names {
+		if i != 0 && len(s.gistJoinDelimiter) != 0 {
+			readers = append(readers, bytes.NewReader(s.gistJoinDelimiter))
+		}
+		file := gist.Files[ghv3.GistFilename(name)]
+		readers = append(readers, newLazyReader(func() (io.Reader, error) {
+			r, err := s.readGistFile(ctx, file)
+			if err != nil {
+				return nil, err
+			}
+			return decodeGistContent(r), nil
+		}))
+	}
+	return io.MultiReader(readers...), nil
+}
@@ -0,0 +1,113 @@
+package putingh_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInGistFiles(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	var writes atomic.Int64
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v3/gists") && (r.Method == http.MethodPost || r.Method == http.MethodPatch) {
+			writes.Add(1)
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	ctx := context.Background()
+
+	fname := filepath.Join(t.TempDir(), "a.txt")
+	err := os.WriteFile(fname, []byte("a from a file"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// a new gist is created with the files from a file and a reader
+	urls, err := putter.PutInGistFiles(ctx, owner, "*", map[string]io.Reader{
+		"a.txt": f,
+		"b.txt": strings.NewReader("b"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGistFiles(t, urls, map[string]string{
+		"a.txt": "a from a file",
+		"b.txt": "b",
+	})
+	if n := writes.Swap(0); n != 1 {
+		t.Fatalf("created the gist in %d requests, want 1", n)
+	}
+	id := gistID(t, putter)
+
+	// update, create and delete in the same edit
+	urls, err = putter.PutInGistFiles(ctx, owner, id, map[string]io.Reader{
+		"a.txt": strings.NewReader("a updated"),
+		"b.txt": nil,
+		"c.txt": strings.NewReader("c"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGistFiles(t, urls, map[string]string{
+		"a.txt": "a updated",
+		"c.txt": "c",
+	})
+	if n := writes.Swap(0); n != 1 {
+		t.Fatalf("edited the gist in %d requests, want 1", n)
+	}
+
+	gists, err := putter.FindGists(ctx, owner, func(*ghv3.Gist) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for name := range gists[0].Files {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "a.txt,c.txt" {
+		t.Fatalf("got files %v, want a.txt and c.txt", names)
+	}
+}
+
+// checkGistFiles checks that urls holds a raw URL for each file of want that reads back its content.
+func checkGistFiles(t *testing.T, urls map[string]string, want map[string]string) {
+	t.Helper()
+	if len(urls) != len(want) {
+		t.Fatalf("got urls %v, want one for each of %v", urls, want)
+	}
+	for name, content := range want {
+		u, ok := urls[name]
+		if !ok {
+			t.Fatalf("no url for %s in %v", name, urls)
+		}
+		resp, err := http.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Fatalf("%s: got %q, want %q", name, got, content)
+		}
+	}
+}
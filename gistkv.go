@@ -0,0 +1,181 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// GistKV uses the files of a gist as a key-value store.
+// Set and Delete are buffered until Flush, which sends them in a single edit.
+type GistKV struct {
+	s      *PutInGH
+	ctx    context.Context
+	gistID string
+
+	mut     sync.Mutex
+	gist    *ghv3.Gist
+	etag    string
+	pending map[string][]byte
+}
+
+// OpenGistKV opens the existing gist gistID of owner as a GistKV, ctx is used by all its requests.
+func (s *PutInGH) OpenGistKV(ctx context.Context, owner, gistID string) (*GistKV, error) {
+	if err := s.checkAllowed("gist", owner, gistID); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	gist, err := s.findGist(ctx, owner, gistID)
+	if err != nil {
+		return nil, err
+	}
+	if gist == nil {
+		return nil, ErrNotFound
+	}
+	return &GistKV{
+		s:       s,
+		ctx:     ctx,
+		gistID:  gist.GetID(),
+		gist:    gist,
+		pending: map[string][]byte{},
+	}, nil
+}
+
+// refresh revalidates the cached gist, it is only downloaded again when it changed.
+func (kv *GistKV) refresh() error {
+	req, err := kv.s.cliv3.NewRequest(http.MethodGet, "gists/"+kv.gistID, nil)
+	if err != nil {
+		return err
+	}
+	if kv.etag != "" {
+		req.Header.Set("If-None-Match", kv.etag)
+	}
+	gist := &ghv3.Gist{}
+	response, err := kv.s.cliv3.Do(kv.ctx, req, gist)
+	if response != nil && response.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	kv.gist = gist
+	kv.etag = response.Header.Get("ETag")
+	return nil
+}
+
+// Get returns the value of key, including a pending Set.
+func (kv *GistKV) Get(key string) ([]byte, error) {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+	if value, ok := kv.pending[key]; ok {
+		if value == nil {
+			return nil, ErrNotFound
+		}
+		return append([]byte(nil), value...), nil
+	}
+	err := kv.refresh()
+	if err != nil {
+		return nil, err
+	}
+	file, ok := kv.gist.Files[ghv3.GistFilename(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r, err := kv.s.readGistFile(kv.ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader(r)
+	return io.ReadAll(decodeGistContent(r))
+}
+
+// Set buffers key to be written with value.
+func (kv *GistKV) Set(key string, value []byte) error {
+	if err := kv.s.checkWritable(); err != nil {
+		return err
+	}
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+	kv.pending[key] = append([]byte{}, value...)
+	return nil
+}
+
+// Delete buffers key to be deleted.
+func (kv *GistKV) Delete(key string) error {
+	if err := kv.s.checkWritable(); err != nil {
+		return err
+	}
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+	if value, ok := kv.pending[key]; ok && value == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if _, ok := kv.gist.Files[ghv3.GistFilename(key)]; !ok {
+		if _, ok := kv.pending[key]; !ok {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		// only pending, nothing to delete in the gist
+		delete(kv.pending, key)
+		return nil
+	}
+	kv.pending[key] = nil
+	return nil
+}
+
+// Keys returns the keys in sorted order, including pending changes.
+func (kv *GistKV) Keys() []string {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+	kv.refresh()
+	keys := []string{}
+	for name := range kv.gist.Files {
+		if value, ok := kv.pending[string(name)]; ok && value == nil {
+			continue
+		}
+		keys = append(keys, string(name))
+	}
+	for key, value := range kv.pending {
+		if _, ok := kv.gist.Files[ghv3.GistFilename(key)]; !ok && value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Flush writes all pending changes in a single edit of the gist.
+func (kv *GistKV) Flush() error {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+	if len(kv.pending) == 0 {
+		return nil
+	}
+	files := make(map[string]*ghv3.GistFile, len(kv.pending))
+	for key, value := range kv.pending {
+		if value == nil {
+			files[key] = nil
+			continue
+		}
+		files[key] = &ghv3.GistFile{
+			Content:  ghv3.String(kv.s.encodeGistContent(value)),
+			Language: kv.s.gistLanguageOf(key),
+		}
+	}
+	err := kv.s.checkGistVisibility(kv.gist)
+	if err != nil {
+		return err
+	}
+	gist, err := kv.s.editGistFiles(kv.ctx, kv.gistID, files)
+	if err != nil {
+		return err
+	}
+	kv.gist = gist
+	kv.etag = ""
+	kv.pending = map[string][]byte{}
+	return nil
+}
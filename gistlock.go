@@ -0,0 +1,107 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithGistOptimisticLock makes gist file puts fail with ErrConflict when the gist got another revision
+// between reading it and editing it.
+// The API has no conditional edit, so the head is checked again right before the edit, and the edit is checked
+// to sit right on top of the revision that was read. When another edit slipped in between,
+// the file is put back to its content in that edit.
+func WithGistOptimisticLock(lock bool) Option {
+	return func(p *PutInGH) {
+		p.gistOptimisticLock = lock
+	}
+}
+
+// gistRevisions returns the latest n revisions of the gist, newest first.
+func (s *PutInGH) gistRevisions(ctx context.Context, id string, n int) ([]string, error) {
+	commits, _, err := s.cliv3.Gists.ListCommits(ctx, id, &ghv3.ListOptions{PerPage: n})
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) > n {
+		commits = commits[:n]
+	}
+	revisions := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		revisions = append(revisions, commit.GetVersion())
+	}
+	return revisions, nil
+}
+
+// gistHead returns the latest revision of the gist, empty when it has none.
+func (s *PutInGH) gistHead(ctx context.Context, id string) (string, error) {
+	revisions, err := s.gistRevisions(ctx, id, 1)
+	if err != nil || len(revisions) == 0 {
+		return "", err
+	}
+	return revisions[0], nil
+}
+
+// checkGistHead returns ErrConflict when the head of the gist is no longer expected.
+func (s *PutInGH) checkGistHead(ctx context.Context, id, expected string) error {
+	head, err := s.gistHead(ctx, id)
+	if err != nil {
+		return err
+	}
+	if head != expected {
+		return fmt.Errorf("%w: gist %s was edited concurrently, head is %q, expected %q", ErrConflict, id, head, expected)
+	}
+	return nil
+}
+
+// checkGistParent returns ErrConflict when the revision before the head of the gist is not expected,
+// that is another edit landed between reading expected and our edit.
+// The file name is then restored to its content in that other edit.
+func (s *PutInGH) checkGistParent(ctx context.Context, id, name, expected string) error {
+	revisions, err := s.gistRevisions(ctx, id, 2)
+	if err != nil {
+		return err
+	}
+	parent := ""
+	if len(revisions) == 2 {
+		parent = revisions[1]
+	}
+	if parent == expected {
+		return nil
+	}
+	err = fmt.Errorf("%w: gist %s was edited concurrently, edit is on top of %q, expected %q", ErrConflict, id, parent, expected)
+	if rerr := s.restoreGistFile(ctx, id, name, parent); rerr != nil {
+		return fmt.Errorf("%w, restore %s: %v", err, name, rerr)
+	}
+	return err
+}
+
+// restoreGistFile sets the file name of the gist back to its content at revision, removing it when it was absent.
+func (s *PutInGH) restoreGistFile(ctx context.Context, id, name, revision string) error {
+	if revision == "" {
+		return fmt.Errorf("no revision to restore")
+	}
+	gist, _, err := s.cliv3.Gists.GetRevision(ctx, id, revision)
+	if err != nil {
+		return err
+	}
+	var file *ghv3.GistFile
+	if old, ok := gist.Files[ghv3.GistFilename(name)]; ok {
+		r, err := s.readGistFile(ctx, old)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(r)
+		closeReader(r)
+		if err != nil {
+			return err
+		}
+		file = &ghv3.GistFile{
+			Content: ghv3.String(string(data)),
+		}
+	}
+	_, err = s.editGistFiles(ctx, id, map[string]*ghv3.GistFile{name: file})
+	return err
+}
@@ -0,0 +1,92 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// raceGist makes racer put "racer" to uri once, right before the request matched by before is served.
+func raceGist(t *testing.T, before func(r *http.Request) bool) error {
+	t.Helper()
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "gist://" + owner + "/*/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("base"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	racer := newPutter(t, srv)
+	var raced atomic.Bool
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if before(r) && raced.CompareAndSwap(false, true) {
+			_, err := racer.PutIn(ctx, uri, strings.NewReader("racer"))
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		handler.ServeHTTP(rw, r)
+	})
+
+	locked := newPutter(t, srv, putingh.WithGistOptimisticLock(true))
+	_, err = locked.PutIn(ctx, uri, strings.NewReader("locked"))
+	if !raced.Load() {
+		t.Fatal("the racing edit did not run")
+	}
+	got, gerr := putter.GetBytes(ctx, uri)
+	if gerr != nil {
+		t.Fatal(gerr)
+	}
+	if string(got) != "racer" {
+		t.Fatalf("got %q, want the racing edit %q", got, "racer")
+	}
+	return err
+}
+
+func TestGistOptimisticLockBeforeEdit(t *testing.T) {
+	var commits atomic.Int32
+	err := raceGist(t, func(r *http.Request) bool {
+		// the second listing is the check right before the edit
+		return strings.HasSuffix(r.URL.Path, "/commits") && commits.Add(1) == 2
+	})
+	if !errors.Is(err, putingh.ErrConflict) {
+		t.Fatalf("got %v, want ErrConflict", err)
+	}
+}
+
+func TestGistOptimisticLockDuringEdit(t *testing.T) {
+	err := raceGist(t, func(r *http.Request) bool {
+		return r.Method == http.MethodPatch
+	})
+	if !errors.Is(err, putingh.ErrConflict) {
+		t.Fatalf("got %v, want ErrConflict", err)
+	}
+}
+
+func TestGistOptimisticLock(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "gist://" + owner + "/*/name.txt"
+	locked := newPutter(t, srv, putingh.WithGistOptimisticLock(true))
+	for _, content := range []string{"v1", "v2"} {
+		_, err := locked.PutIn(ctx, uri, strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := locked.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "v2" {
+		t.Fatalf("got %q, want %q", got, "v2")
+	}
+}
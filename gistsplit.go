@@ -0,0 +1,184 @@
+package putingh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+const (
+	// gistPartSize is the size GitHub still returns inline for a gist file.
+	gistPartSize = 1 << 20
+
+	gistManifestSuffix = ".manifest"
+	gistPartSuffix     = ".part"
+)
+
+// WithGistAutoSplit writes gist content larger than a gist file into name.part0, name.part1, ...
+// with a name.manifest listing them, and reassembles them when name is read.
+func WithGistAutoSplit(split bool) Option {
+	return func(p *PutInGH) {
+		p.gistAutoSplit = split
+	}
+}
+
+type gistManifest struct {
+	Size  int      `json:"size"`
+	Parts []string `json:"parts"`
+}
+
+// splitGistContent cuts data into parts of at most size bytes without splitting a UTF-8 sequence.
+func splitGistContent(data string, size int) []string {
+	var parts []string
+	for len(data) > size {
+		n := size
+		for n > 0 && !utf8.RuneStart(data[n]) {
+			n--
+		}
+		if n == 0 {
+			n = size
+		}
+		parts = append(parts, data[:n])
+		data = data[n:]
+	}
+	return append(parts, data)
+}
+
+func isGistPart(filename, name string) bool {
+	index, ok := strings.CutPrefix(filename, name+gistPartSuffix)
+	if !ok || index == "" {
+		return false
+	}
+	_, err := strconv.Atoi(index)
+	return err == nil
+}
+
+func isGistSplit(gist *ghv3.Gist, name string) bool {
+	if gist == nil {
+		return false
+	}
+	_, ok := gist.Files[ghv3.GistFilename(name+gistManifestSuffix)]
+	return ok
+}
+
+// putInGistSplit writes data as parts of name in a single request, replacing the file or the parts written before.
+// Content that fits a single file again is written to name and the parts are removed.
+func (s *PutInGH) putInGistSplit(ctx context.Context, oriGist *ghv3.Gist, name, data string) (string, error) {
+	if s.requireChange && oriGist != nil {
+		unchanged, err := s.gistSplitUnchanged(ctx, oriGist, name, data)
+		if err != nil {
+			return "", err
+		}
+		if unchanged {
+			return "", fmt.Errorf("%w: %s", ErrNoChange, name)
+		}
+	}
+
+	files := map[string]*ghv3.GistFile{}
+	if oriGist != nil {
+		for filename := range oriGist.Files {
+			if filename == ghv3.GistFilename(name) || isGistPart(string(filename), name) {
+				files[string(filename)] = nil
+			}
+		}
+	}
+
+	target := name
+	if len(data) <= gistPartSize {
+		files[name] = &ghv3.GistFile{
+			Content:  ghv3.String(data),
+			Language: s.gistLanguageOf(name),
+		}
+		files[name+gistManifestSuffix] = nil
+	} else {
+		target = name + gistManifestSuffix
+		manifest := gistManifest{
+			Size: len(data),
+		}
+		for i, part := range splitGistContent(data, gistPartSize) {
+			partName := fmt.Sprintf("%s%s%d", name, gistPartSuffix, i)
+			files[partName] = &ghv3.GistFile{
+				Content: ghv3.String(part),
+			}
+			manifest.Parts = append(manifest.Parts, partName)
+		}
+		m, err := json.Marshal(manifest)
+		if err != nil {
+			return "", err
+		}
+		files[target] = &ghv3.GistFile{
+			Content: ghv3.String(string(m)),
+		}
+	}
+
+	var gist *ghv3.Gist
+	var err error
+	if oriGist == nil {
+		create := map[ghv3.GistFilename]ghv3.GistFile{}
+		for filename, file := range files {
+			if file != nil {
+				create[ghv3.GistFilename(filename)] = *file
+			}
+		}
+		gist, _, err = s.cliv3.Gists.Create(ctx, &ghv3.Gist{
+			Public:      ghv3.Bool(true),
+			Files:       create,
+			Description: s.gistDescriptionOf(name),
+		})
+	} else {
+		gist, err = s.editGistFiles(ctx, oriGist.GetID(), files)
+	}
+	if err != nil {
+		return "", err
+	}
+	file := gist.Files[ghv3.GistFilename(target)]
+	raw := file.GetRawURL()
+	return strings.SplitN(raw, "/raw/", 2)[0] + "/raw/" + target, nil
+}
+
+// gistSplitUnchanged reports whether name of gist already holds data, as a single file or as parts.
+func (s *PutInGH) gistSplitUnchanged(ctx context.Context, gist *ghv3.Gist, name, data string) (bool, error) {
+	if _, ok := gist.Files[ghv3.GistFilename(name)]; ok {
+		return s.gistFileUnchanged(ctx, gist, name, data)
+	}
+	if !isGistSplit(gist, name) {
+		return false, nil
+	}
+	r, err := s.getFromGistSplit(ctx, gist, name)
+	if err != nil {
+		return false, err
+	}
+	defer closeReader(r)
+	return readersEqual(r, strings.NewReader(data))
+}
+
+// getFromGistSplit reassembles the parts listed in the manifest of name.
+func (s *PutInGH) getFromGistSplit(ctx context.Context, gist *ghv3.Gist, name string) (io.Reader, error) {
+	r, err := s.readGistFile(ctx, gist.Files[ghv3.GistFilename(name+gistManifestSuffix)])
+	if err != nil {
+		return nil, err
+	}
+	var manifest gistManifest
+	err = json.NewDecoder(r).Decode(&manifest)
+	closeReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("gist manifest %s: %w", name+gistManifestSuffix, err)
+	}
+	readers := make([]io.Reader, 0, len(manifest.Parts))
+	for _, part := range manifest.Parts {
+		file, ok := gist.Files[ghv3.GistFilename(part)]
+		if !ok {
+			return nil, fmt.Errorf("gist part %s: %w", part, ErrNotFound)
+		}
+		readers = append(readers, newLazyReader(func() (io.Reader, error) {
+			return s.readGistFile(ctx, file)
+		}))
+	}
+	return io.MultiReader(readers...), nil
+}
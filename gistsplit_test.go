@@ -0,0 +1,53 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGistAutoSplit(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithGistAutoSplit(true))
+	ctx := context.Background()
+	// spans three parts of 1MiB
+	content := bytes.Repeat([]byte("0123456789abcdef"), (2<<20+1<<19)/16)
+	uri := "gist://" + owner + "/*/large.bin"
+	_, err := putter.PutBytes(ctx, uri, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gists, err := putter.FindGists(ctx, owner, func(*ghv3.Gist) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gists) != 1 {
+		t.Fatalf("got %d gists, want 1", len(gists))
+	}
+	names := []string{}
+	for name := range gists[0].Files {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	want := "large.bin.manifest,large.bin.part0,large.bin.part1,large.bin.part2"
+	if strings.Join(names, ",") != want {
+		t.Fatalf("got files %v, want %s", names, want)
+	}
+
+	for _, uri := range []string{uri, "gist://" + owner + "/" + gists[0].GetID() + "/large.bin"} {
+		got, err := putter.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Fatalf("%s: got %d bytes back, want the %d put", uri, len(got), len(content))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// PutInGitCommit puts r in the git repository like PutIn and also returns the hash of the commit holding it,
+// which is the current tip of branch when the content was unchanged.
+func (s *PutInGH) PutInGitCommit(ctx context.Context, owner, repo, branch, name string, r io.Reader) (string, string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", "", err
+	}
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return "", "", err
+	}
+	if mode := s.lineEndingOf(name); mode != LineEndingAsIs {
+		r = normalizeLineEnding(r, mode)
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, "git")
+	defer cancel()
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return "", "", err
+	}
+	rawURL, hash, err := s.putInGitCommit(ctx, owner, repo, branch, name, r)
+	if err != nil {
+		return "", "", err
+	}
+	if hash.IsZero() {
+		head, response, err := s.cliv3.Repositories.GetCommitSHA1(ctx, owner, repo, branch, "")
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				return "", "", fmt.Errorf("%w: branch %s", ErrNotFound, branch)
+			}
+			return "", "", err
+		}
+		return rawURL, head, nil
+	}
+	return rawURL, hash.String(), nil
+}
@@ -0,0 +1,65 @@
+package putingh_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInGitCommit(t *testing.T) {
+	for name, opts := range map[string]func(t *testing.T) []putingh.Option{
+		"worktree": func(t *testing.T) []putingh.Option { return nil },
+		"bare": func(t *testing.T) []putingh.Option {
+			return []putingh.Option{putingh.WithBareRepoPath(bareRepo(t))}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv, _ := putinghtest.NewServer(t)
+			pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+			putter := newPutter(t, srv, opts(t)...)
+			ctx := context.Background()
+
+			// the name is not parsed as a URI
+			file := "100% done?#1.txt"
+			rawURL, hash, err := putter.PutInGitCommit(ctx, owner, "repo", "main", file, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(rawURL, "/raw/main/"+file) {
+				t.Fatalf("got raw URL %q", rawURL)
+			}
+			head := headCommit(t, srv, "repo", "main")
+			if hash != head.Hash.String() {
+				t.Fatalf("got commit %s, want the tip %s", hash, head.Hash)
+			}
+			if _, err := head.File(file); err != nil {
+				t.Fatalf("%q is not in the commit: %v", file, err)
+			}
+
+			// unchanged content returns the current tip
+			_, again, err := putter.PutInGitCommit(ctx, owner, "repo", "main", file, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != hash {
+				t.Fatalf("got commit %s for unchanged content, want %s", again, hash)
+			}
+		})
+	}
+}
+
+// bareRepo returns the path of a new empty bare repository.
+func bareRepo(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "bare.git")
+	_, err := gogit.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
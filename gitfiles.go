@@ -0,0 +1,23 @@
+package putingh
+
+import (
+	"context"
+	"io"
+	"sort"
+)
+
+// PutInGitFiles writes files to branch in a single commit and push, so the branch never holds only some of them.
+// The name given to the commit message and option callbacks lists the files joined by ", ".
+// Nothing is committed when none of the files changed. It returns the raw URL of each file, sorted by name.
+func (s *PutInGH) PutInGitFiles(ctx context.Context, owner, repo, branch string, files map[string]io.Reader) ([]string, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b := s.Batch(owner, repo, branch)
+	for _, name := range names {
+		b.Add(name, files[name])
+	}
+	return b.Commit(ctx)
+}
@@ -0,0 +1,79 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInGitFiles(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+	ctx := context.Background()
+	files := func() map[string]io.Reader {
+		return map[string]io.Reader{
+			"b.txt":     strings.NewReader("b"),
+			"dir/a.txt": strings.NewReader("a"),
+		}
+	}
+
+	before := headCommit(t, srv, "repo", "main")
+	urls, err := putter.PutInGitFiles(ctx, owner, "repo", "main", files())
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := headCommit(t, srv, "repo", "main")
+	if len(head.ParentHashes) != 1 || head.ParentHashes[0] != before.Hash {
+		t.Fatalf("got parents %v, want a single commit on %s", head.ParentHashes, before.Hash)
+	}
+
+	// the same URLs as a batch of the files
+	b := putter.Batch(owner, "repo", "main")
+	b.Add("b.txt", strings.NewReader("b"))
+	b.Add("dir/a.txt", strings.NewReader("a"))
+	want, err := b.Commit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(urls, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %q, want %q", urls, want)
+	}
+	if again := headCommit(t, srv, "repo", "main"); again.Hash != head.Hash {
+		t.Fatal("committed unchanged files")
+	}
+	for name, want := range map[string]string{"b.txt": "b", "dir/a.txt": "a"} {
+		got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPutInGitFilesSchemeTimeout(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info/refs") {
+			<-r.Context().Done()
+			return
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	putter := newPutter(t, srv, putingh.WithSchemeTimeout("git", 50*time.Millisecond))
+	_, err := putter.PutInGitFiles(context.Background(), owner, "repo", "main", map[string]io.Reader{
+		"name.txt": strings.NewReader("content"),
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
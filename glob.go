@@ -0,0 +1,88 @@
+package putingh
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+)
+
+// DeleteGlob removes the tracked files matching pattern in one commit and returns the removed paths.
+// The pattern is matched like path.Match per segment, with ** matching any number of segments.
+func (s *PutInGH) DeleteGlob(ctx context.Context, owner, repo, branch, pattern string) ([]string, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return nil, err
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := repository.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range idx.Entries {
+		if !matchGlob(pattern, entry.Name) {
+			continue
+		}
+		fname, err := safeJoinResolved(dir, entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		err = os.Remove(fname)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		names = append(names, entry.Name)
+	}
+	if len(names) == 0 {
+		return names, nil
+	}
+	changed, _, err := s.commitGit(ctx, repository, owner, repo, branch, pattern, dir, names)
+	return changed, err
+}
+
+// matchGlob reports whether name matches pattern, ** matches zero or more path segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
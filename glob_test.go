@@ -0,0 +1,101 @@
+package putingh_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestDeleteGlob(t *testing.T) {
+	files := map[string]string{
+		"README.md":          "readme",
+		"a.log":              "log",
+		"docs/b.log":         "log",
+		"docs/deep/c.log":    "log",
+		"docs/deep/keep.txt": "keep",
+		"other/d.log":        "log",
+	}
+	for pattern, want := range map[string][]string{
+		"*.log":         {"a.log"},
+		"docs/*.log":    {"docs/b.log"},
+		"docs/**/*.log": {"docs/b.log", "docs/deep/c.log"},
+		"**/*.log":      {"a.log", "docs/b.log", "docs/deep/c.log", "other/d.log"},
+		"**/deep":       nil,
+		"*.tmp":         nil,
+	} {
+		t.Run(pattern, func(t *testing.T) {
+			srv, putter := putinghtest.NewServer(t)
+			pushGit(t, srv, "repo", "main", files)
+			before := headCommit(t, srv, "repo", "main")
+
+			removed, err := putter.DeleteGlob(context.Background(), owner, "repo", "main", pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(removed)
+			if strings.Join(removed, ",") != strings.Join(want, ",") {
+				t.Fatalf("removed %v, want %v", removed, want)
+			}
+
+			commit := headCommit(t, srv, "repo", "main")
+			if len(want) == 0 {
+				if commit.Hash != before.Hash {
+					t.Fatalf("committed %s with nothing matched", commit.Hash)
+				}
+				return
+			}
+			if len(commit.ParentHashes) != 1 || commit.ParentHashes[0] != before.Hash {
+				t.Fatalf("removed in more than one commit")
+			}
+			tree, err := commit.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name := range files {
+				_, err := tree.File(name)
+				gone := err != nil
+				if gone != contains(want, name) {
+					t.Errorf("%s: removed is %v", name, gone)
+				}
+			}
+		})
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeleteGlobKeepsCommitOptions(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"a.log": "log"})
+	shared := &gogit.CommitOptions{
+		Author: &object.Signature{Name: "shared", Email: "shared@example.com", When: time.Now()},
+	}
+	putter := newPutter(t, srv, putingh.WithGitCommitOptions(func(owner, repo, branch, name, path string) *gogit.CommitOptions {
+		return shared
+	}))
+	// removing the last file commits an empty tree
+	_, err := putter.DeleteGlob(context.Background(), owner, "repo", "main", "*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.AllowEmptyCommits {
+		t.Fatal("changed the options returned by the callback")
+	}
+	if head := headCommit(t, srv, "repo", "main"); head.Author.Name != "shared" {
+		t.Fatalf("got author %q, want the one of the options", head.Author.Name)
+	}
+}
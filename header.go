@@ -0,0 +1,73 @@
+package putingh
+
+import (
+	"context"
+	"net/http"
+
+	gogithttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// WithRequestHeader sets the header key to value on every GitHub API, download, LFS and git request,
+// such as the Referer or Origin an API gateway allowlists.
+func WithRequestHeader(key, value string) Option {
+	return func(p *PutInGH) {
+		if p.requestHeader == nil {
+			p.requestHeader = http.Header{}
+		}
+		p.requestHeader.Set(key, value)
+	}
+}
+
+// headerTransport sets the configured headers on a copy of each request.
+type headerTransport struct {
+	header http.Header
+	base   http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range t.header {
+		req.Header[key] = values
+	}
+	return t.base.RoundTrip(req)
+}
+
+func (s *PutInGH) withHeaderTransport(cli *http.Client) *http.Client {
+	if len(s.requestHeader) == 0 {
+		return cli
+	}
+	base := cli.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := *cli
+	c.Transport = &headerTransport{
+		header: s.requestHeader,
+		base:   base,
+	}
+	return &c
+}
+
+// gitAuth is the basic auth of git requests, go-git applies it to each request
+// so it also carries the configured headers the transport can not be given.
+type gitAuth struct {
+	gogithttp.BasicAuth
+	header http.Header
+}
+
+func (a *gitAuth) SetAuth(r *http.Request) {
+	a.BasicAuth.SetAuth(r)
+	for key, values := range a.header {
+		r.Header[key] = values
+	}
+}
+
+func (s *PutInGH) gitBasicAuth(ctx context.Context, owner string) *gitAuth {
+	return &gitAuth{
+		BasicAuth: gogithttp.BasicAuth{
+			Username: owner,
+			Password: s.tokenOf(ctx),
+		},
+		header: s.requestHeader,
+	}
+}
@@ -0,0 +1,84 @@
+package putingh_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestRequestHeader(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+
+	const referer, origin = "https://gateway.example/putingh", "https://gateway.example"
+	var (
+		mut     sync.Mutex
+		seen    = map[string]bool{}
+		missing []string
+	)
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		kind := "api"
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/gist-raw/"), strings.Contains(r.URL.Path, "/releases/download/"):
+			kind = "download"
+		case strings.HasPrefix(r.URL.Path, "/api/uploads/"):
+			kind = "upload"
+		case strings.HasSuffix(r.URL.Path, "/info/refs"),
+			strings.HasSuffix(r.URL.Path, "/git-upload-pack"),
+			strings.HasSuffix(r.URL.Path, "/git-receive-pack"):
+			kind = "git"
+		}
+		mut.Lock()
+		seen[kind] = true
+		if r.Header.Get("Referer") != referer || r.Header.Get("Origin") != origin {
+			missing = append(missing, r.Method+" "+r.URL.Path)
+		}
+		mut.Unlock()
+		handler.ServeHTTP(rw, r)
+	})
+
+	putter := newPutter(t, srv,
+		putingh.WithRequestHeader("Referer", referer),
+		putingh.WithRequestHeader("Origin", origin),
+		// read gists through their raw URL
+		putingh.WithGistStreamThreshold(1),
+	)
+	ctx := context.Background()
+	for _, uri := range []string{
+		"git://" + owner + "/repo/main/name.txt",
+		"gist://" + owner + "/*/name.txt",
+		"asset://" + owner + "/repo/v1/name.txt",
+	} {
+		_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.GetBytes(ctx, "gist://"+owner+"/"+gistID(t, putter)+"/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.GetBytes(ctx, "asset://"+owner+"/repo/v1/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kind := range []string{"api", "download", "upload", "git"} {
+		if !seen[kind] {
+			t.Fatalf("made no %s request", kind)
+		}
+	}
+	if len(missing) != 0 {
+		t.Fatalf("sent without the headers: %q", missing)
+	}
+}
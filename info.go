@@ -0,0 +1,147 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// FileInfo describes the last commit that changed a git file.
+type FileInfo struct {
+	SHA    string
+	Author string
+	Email  string
+	Date   time.Time
+}
+
+// GetFromGitWithInfo returns the content of name together with the last commit that changed it.
+func (s *PutInGH) GetFromGitWithInfo(ctx context.Context, owner, repo, branch, name string) (io.Reader, *FileInfo, error) {
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return nil, nil, err
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, "git")
+	defer cancel()
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	commit, err := lastGitCommit(repository, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := s.readGitFile(dir, name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, nil, err
+	}
+	return r, &FileInfo{
+		SHA:    commit.Hash.String(),
+		Author: commit.Author.Name,
+		Email:  commit.Author.Email,
+		Date:   commit.Author.When,
+	}, nil
+}
+
+// lastGitCommit returns the last commit on HEAD that changed name.
+func lastGitCommit(repository *gogit.Repository, name string) (*object.Commit, error) {
+	head, err := repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	iter, err := repository.Log(&gogit.LogOptions{
+		From:     head.Hash(),
+		FileName: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	commit, err := iter.Next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	return commit, nil
+}
+
+// GitFileVersions calls fn with every version of name, newest first, until fn returns false.
+// Commits deleting name are skipped.
+func (s *PutInGH) GitFileVersions(ctx context.Context, owner, repo, branch, name string, fn func(sha string, r io.Reader) bool) error {
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return err
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, "git")
+	defer cancel()
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	_, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	iter, err := repository.Log(&gogit.LogOptions{
+		From:     head.Hash(),
+		FileName: &name,
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for {
+		commit, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		file, err := commit.File(name)
+		if err != nil {
+			if errors.Is(err, object.ErrFileNotFound) {
+				continue
+			}
+			return err
+		}
+		r, err := file.Reader()
+		if err != nil {
+			return err
+		}
+		next := fn(commit.Hash.String(), r)
+		r.Close()
+		if !next {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// newStalledGitPutter returns a putter whose git fetches hang until their context is done.
+func newStalledGitPutter(t *testing.T) *putingh.PutInGH {
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"name.txt": "git"})
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info/refs") {
+			<-r.Context().Done()
+			return
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	return newPutter(t, srv, putingh.WithSchemeTimeout("git", 50*time.Millisecond))
+}
+
+func TestGetFromGitWithInfoSchemeTimeout(t *testing.T) {
+	putter := newStalledGitPutter(t)
+	_, _, err := putter.GetFromGitWithInfo(context.Background(), owner, "repo", "main", "name.txt")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestGitFileVersionsSchemeTimeout(t *testing.T) {
+	putter := newStalledGitPutter(t)
+	err := putter.GitFileVersions(context.Background(), owner, "repo", "main", "name.txt", func(string, io.Reader) bool { return true })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -1,7 +1,15 @@
 package putingh
 
 import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 func newReaderWithAutoCloser(rc io.ReadCloser) io.Reader {
@@ -11,13 +19,274 @@ func newReaderWithAutoCloser(rc io.ReadCloser) io.Reader {
 }
 
 type readerWithAutoCloser struct {
-	rc io.ReadCloser
+	rc  io.ReadCloser
+	eof bool
 }
 
 func (r *readerWithAutoCloser) Read(p []byte) (n int, err error) {
+	if r.eof {
+		// already closed, callers such as bufio may read again after EOF
+		return 0, io.EOF
+	}
 	n, err = r.rc.Read(p)
 	if err == io.EOF {
+		r.eof = true
 		r.rc.Close()
 	}
 	return n, err
 }
+
+func (r *readerWithAutoCloser) Close() error {
+	return r.rc.Close()
+}
+
+// newLazyReader defers open until the first read, so files behind a raw URL are
+// only requested when the reader gets to them.
+func newLazyReader(open func() (io.Reader, error)) io.Reader {
+	return &lazyReader{
+		open: open,
+	}
+}
+
+type lazyReader struct {
+	open func() (io.Reader, error)
+	r    io.Reader
+	err  error
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.r == nil && l.err == nil {
+		l.r, l.err = l.open()
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return l.r.Read(p)
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// keepReaderLen returns wrapped, which reads through r, seeking or telling its Len like r does,
+// so a put can still tell the length of r. rewind is called when r is sought back to where it was.
+func keepReaderLen(wrapped, r io.Reader, rewind func()) io.Reader {
+	switch r := r.(type) {
+	case io.ReadSeeker:
+		start, err := r.Seek(0, io.SeekCurrent)
+		if err == nil {
+			return &seekingReader{Reader: wrapped, seeker: r, start: start, rewind: rewind}
+		}
+	case interface{ Len() int }:
+		return &lenReader{Reader: wrapped, len: r}
+	}
+	return wrapped
+}
+
+type seekingReader struct {
+	io.Reader
+	seeker io.Seeker
+	start  int64
+	rewind func()
+}
+
+func (s *seekingReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := s.seeker.Seek(offset, whence)
+	if err == nil && pos == s.start {
+		s.rewind()
+	}
+	return pos, err
+}
+
+type lenReader struct {
+	io.Reader
+	len interface{ Len() int }
+}
+
+func (l *lenReader) Len() int {
+	return l.len.Len()
+}
+
+// decodeContentEncoding replaces the body of resp with its decompressed form,
+// the transport only does this itself when it negotiated the encoding.
+func decodeContentEncoding(resp *http.Response) error {
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		r, err = zlib.NewReader(resp.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	resp.Body = &readCloser{
+		Reader:  r,
+		closers: []io.Closer{r, resp.Body},
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+func closeReader(r io.Reader) error {
+	if c, ok := r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// newLineRangeReader returns only the lines [start, end] of r, counted from 1.
+func newLineRangeReader(r io.Reader, start, end int) io.Reader {
+	if start < 1 {
+		start = 1
+	}
+	return &lineRangeReader{
+		src:   r,
+		r:     bufio.NewReader(r),
+		line:  1,
+		start: start,
+		end:   end,
+	}
+}
+
+type lineRangeReader struct {
+	src   io.Reader
+	r     *bufio.Reader
+	line  int
+	start int
+	end   int
+	buf   []byte
+	err   error
+}
+
+func (l *lineRangeReader) Read(p []byte) (int, error) {
+	for len(l.buf) == 0 {
+		if l.err != nil {
+			return 0, l.err
+		}
+		if l.line > l.end {
+			l.err = io.EOF
+			closeReader(l.src)
+			return 0, l.err
+		}
+
+		chunk, err := l.r.ReadSlice('\n')
+		if l.line >= l.start {
+			l.buf = append(l.buf[:0], chunk...)
+		}
+		if len(chunk) != 0 && chunk[len(chunk)-1] == '\n' {
+			l.line++
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			l.err = err
+		}
+	}
+	n := copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+func (s *PutInGH) limitReader(r io.Reader) io.Reader {
+	if s.maxContentSize <= 0 {
+		return r
+	}
+	return &limitedReader{
+		r:     r,
+		limit: s.maxContentSize,
+		n:     s.maxContentSize,
+	}
+}
+
+// limitedReader fails with ErrContentTooLarge once more than limit bytes are read.
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, fmt.Errorf("%w: limit is %d bytes", ErrContentTooLarge, l.limit)
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n + int(l.n), fmt.Errorf("%w: limit is %d bytes", ErrContentTooLarge, l.limit)
+	}
+	return n, err
+}
+
+func withinDir(dir, path string) bool {
+	dir = filepath.Clean(dir)
+	path = filepath.Clean(path)
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func safeJoin(dir string, elem ...string) (string, error) {
+	name := filepath.Join(elem...)
+	if filepath.IsAbs(name) || strings.HasPrefix(filepath.ToSlash(name), "/") {
+		return "", fmt.Errorf("%w: %q is absolute", ErrInvalidPath, name)
+	}
+	path := filepath.Join(dir, name)
+	if !withinDir(dir, path) {
+		return "", fmt.Errorf("%w: %q escapes %q", ErrInvalidPath, name, dir)
+	}
+	return path, nil
+}
+
+// safeJoinResolved is safeJoin that also resolves the symlinks of the deepest existing parent of name,
+// so a write or removal through a symlinked directory can not land outside of dir.
+func safeJoinResolved(dir, name string) (string, error) {
+	fname, err := safeJoin(dir, name)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	parent := filepath.Dir(fname)
+	for parent != filepath.Clean(dir) && withinDir(dir, parent) {
+		_, err := os.Lstat(parent)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent = filepath.Dir(parent)
+	}
+	real, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+	if !withinDir(root, real) {
+		return "", fmt.Errorf("%w: %q resolves outside of the worktree", ErrInvalidPath, name)
+	}
+	return fname, nil
+}
@@ -0,0 +1,126 @@
+package putingh_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestPutInGitRejectsEscapingNames(t *testing.T) {
+	tmp := t.TempDir()
+	srv, putter := putinghtest.NewServer(t, putingh.WithTmpDir(tmp))
+	outside := t.TempDir()
+	// as go-git checks it out, an absolute target would be kept inside the worktree
+	target, err := filepath.Rel(filepath.Join(tmp, "git", owner, "repo", "main"), outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"README.md": "readme",
+		"linkdir":   "symlink:" + target,
+	})
+
+	ctx := context.Background()
+	for _, name := range []string{
+		"../escape.txt",
+		"dir/../../escape.txt",
+		"/etc/escape.txt",
+		"linkdir/pwn.txt",
+		"linkdir/nested/pwn.txt",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/"+name, strings.NewReader("pwn"))
+			if !errors.Is(err, putingh.ErrInvalidPath) {
+				t.Fatalf("got %v, want ErrInvalidPath", err)
+			}
+		})
+	}
+	entries, err := os.ReadDir(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("wrote %d entries outside of the worktree", len(entries))
+	}
+}
+
+func TestPutInGitNestedName(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/a/b/c.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestGetFromDecodesContentEncoding(t *testing.T) {
+	encoders := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser {
+			return gzip.NewWriter(w)
+		},
+		// HTTP deflate is the zlib format
+		"deflate": func(w io.Writer) io.WriteCloser {
+			return zlib.NewWriter(w)
+		},
+	}
+	for encoding, encoder := range encoders {
+		t.Run(encoding, func(t *testing.T) {
+			srv, putter := putinghtest.NewServer(t)
+			ctx := context.Background()
+			uri := "gist://" + owner + "/*/name.txt"
+			_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler := srv.Config.Handler
+			srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if !strings.HasPrefix(r.URL.Path, "/gist-raw/") {
+					handler.ServeHTTP(rw, r)
+					return
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+				var buf bytes.Buffer
+				w := encoder(&buf)
+				w.Write(rec.Body.Bytes())
+				w.Close()
+				rw.Header().Set("Content-Encoding", encoding)
+				rw.Write(buf.Bytes())
+			})
+
+			// asking for the encoding keeps the transport from decoding it
+			reader := newPutter(t, srv,
+				putingh.WithGistStreamThreshold(1),
+				putingh.WithRequestHeader("Accept-Encoding", encoding),
+			)
+			got, err := reader.GetBytes(ctx, uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != "content" {
+				t.Fatalf("got %q, want %q", got, "content")
+			}
+		})
+	}
+}
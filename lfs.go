@@ -0,0 +1,167 @@
+package putingh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// WithResolveLFS makes GetFromGit return the content of Git LFS pointer files,
+// downloaded through the LFS batch API of the repository.
+func WithResolveLFS(resolve bool) Option {
+	return func(p *PutInGH) {
+		p.resolveLFS = resolve
+	}
+}
+
+type lfsObject struct {
+	OID     string                     `json:"oid"`
+	Size    int64                      `json:"size"`
+	Actions map[string]lfsObjectAction `json:"actions,omitempty"`
+	Error   *lfsObjectError            `json:"error,omitempty"`
+}
+
+type lfsObjectAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type lfsObjectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lfsBatch struct {
+	Operation string      `json:"operation,omitempty"`
+	Transfers []string    `json:"transfers,omitempty"`
+	Objects   []lfsObject `json:"objects"`
+}
+
+// resolveLFSPointer returns r unchanged unless it is an LFS pointer, then the object it points to.
+func (s *PutInGH) resolveLFSPointer(ctx context.Context, owner, repo string, r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(lfsPointerVersion))
+	if string(head) != lfsPointerVersion {
+		return &readCloser{
+			Reader: br,
+			closers: []io.Closer{
+				closerFunc(func() error {
+					return closeReader(r)
+				}),
+			},
+		}, nil
+	}
+	// pointers are well below 1KiB
+	pointer, err := io.ReadAll(io.LimitReader(br, 1024))
+	closeReader(r)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := parseLFSPointer(pointer)
+	if !ok {
+		return bytes.NewReader(pointer), nil
+	}
+	return s.downloadLFSObject(ctx, owner, repo, obj)
+}
+
+func parseLFSPointer(data []byte) (lfsObject, bool) {
+	var obj lfsObject
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok {
+				return obj, false
+			}
+			obj.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return obj, false
+			}
+			obj.Size = size
+		}
+	}
+	return obj, obj.OID != ""
+}
+
+func (s *PutInGH) downloadLFSObject(ctx context.Context, owner, repo string, obj lfsObject) (io.Reader, error) {
+	body, err := json.Marshal(lfsBatch{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsObject{obj},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.gitURL(owner, repo)+".git/info/lfs/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.git-lfs+json")
+	req.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+	auth := s.gitBasicAuth(ctx, owner)
+	req.SetBasicAuth(auth.Username, auth.Password)
+
+	// LFS wants basic auth, the API client would replace it with the OAuth token
+	cli := s.lfsClient()
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lfs batch %s/%s: %s", owner, repo, resp.Status)
+	}
+	var batch lfsBatch
+	err = json.NewDecoder(resp.Body).Decode(&batch)
+	if err != nil {
+		return nil, fmt.Errorf("lfs batch %s/%s: %w", owner, repo, err)
+	}
+	if len(batch.Objects) == 0 {
+		return nil, fmt.Errorf("%w: lfs object %s", ErrNotFound, obj.OID)
+	}
+	got := batch.Objects[0]
+	if got.Error != nil {
+		if got.Error.Code == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: lfs object %s", ErrNotFound, obj.OID)
+		}
+		return nil, fmt.Errorf("lfs object %s: %d %s", obj.OID, got.Error.Code, got.Error.Message)
+	}
+	download, ok := got.Actions["download"]
+	if !ok {
+		return nil, fmt.Errorf("%w: lfs object %s has no download", ErrNotFound, obj.OID)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, download.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range download.Header {
+		req.Header.Set(k, v)
+	}
+	resp, err = cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("lfs object %s: %s", obj.OID, resp.Status)
+	}
+	return newReaderWithAutoCloser(resp.Body), nil
+}
+
+func (s *PutInGH) lfsClient() *http.Client {
+	return s.withRetryTransport(s.withLimitTransport(s.withHeaderTransport(&http.Client{
+		Timeout: s.httpTimeout,
+	})))
+}
@@ -0,0 +1,72 @@
+package putingh_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestResolveLFS(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	content := "the real content behind the pointer"
+	sum := sha256.Sum256([]byte(content))
+	oid := hex.EncodeToString(sum[:])
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/lfs-objects/"+owner+"/repo/"+oid, strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"large.bin": pointer,
+		"small.txt": "not a pointer",
+	})
+
+	var batchAuth atomic.Value
+	batchAuth.Store("")
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info/lfs/objects/batch") {
+			batchAuth.Store(r.Header.Get("Authorization"))
+		}
+		handler.ServeHTTP(rw, r)
+	})
+
+	ctx := context.Background()
+	for resolve, want := range map[bool]string{
+		false: pointer,
+		true:  content,
+	} {
+		putter := newPutter(t, srv, putingh.WithResolveLFS(resolve))
+		got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/large.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("resolve %v: got %q, want %q", resolve, got, want)
+		}
+		got, err = putter.GetBytes(ctx, "git://"+owner+"/repo/main/small.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "not a pointer" {
+			t.Fatalf("resolve %v: got %q for a plain file", resolve, got)
+		}
+	}
+	if batchAuth.Load().(string) == "" {
+		t.Fatal("the batch request was sent without the token")
+	}
+}
@@ -0,0 +1,86 @@
+package putingh
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// WithMaxConcurrentRequests allows at most n GitHub API, download and LFS requests in flight at once,
+// a request waits for a free slot or its context and holds it until its response body is drained or closed.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(p *PutInGH) {
+		if n <= 0 {
+			p.requestLimit = nil
+			return
+		}
+		p.requestLimit = semaphore.NewWeighted(int64(n))
+	}
+}
+
+// limitTransport bounds the requests in flight with a semaphore shared by all clients.
+type limitTransport struct {
+	sem  *semaphore.Weighted
+	base http.RoundTripper
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := t.sem.Acquire(req.Context(), 1)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		t.sem.Release(1)
+		return resp, err
+	}
+	resp.Body = &limitBody{
+		ReadCloser: resp.Body,
+		release: func() {
+			t.sem.Release(1)
+		},
+	}
+	return resp, nil
+}
+
+// limitBody frees the slot of its request once the body is drained or closed.
+type limitBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *limitBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.release)
+	}
+	return n, err
+}
+
+func (b *limitBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func (s *PutInGH) withLimitTransport(cli *http.Client) *http.Client {
+	if s.requestLimit == nil {
+		return cli
+	}
+	base := cli.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := *cli
+	c.Transport = &limitTransport{
+		sem:  s.requestLimit,
+		base: base,
+	}
+	return &c
+}
@@ -0,0 +1,144 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// inFlightTransport tracks the most requests it had in flight at once, holding each for delay.
+type inFlightTransport struct {
+	base     http.RoundTripper
+	delay    time.Duration
+	inFlight atomic.Int64
+	max      atomic.Int64
+}
+
+func (c *inFlightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		max := c.max.Load()
+		if n <= max || c.max.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	return c.base.RoundTrip(req)
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "gist://" + owner + "/" + gistID(t, putter) + "/name.txt"
+
+	const limit = 2
+	instrumented := &inFlightTransport{delay: 20 * time.Millisecond}
+	limited := newPutter(t, srv,
+		putingh.WithHTTPClient(func(cli *http.Client) *http.Client {
+			c := *cli
+			instrumented.base = cli.Transport
+			c.Transport = instrumented
+			return &c
+		}),
+		putingh.WithMaxConcurrentRequests(limit),
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := limited.GetBytes(ctx, uri)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if max := instrumented.max.Load(); max > limit {
+		t.Fatalf("had %d requests in flight, want at most %d", max, limit)
+	}
+	if max := instrumented.max.Load(); max < limit {
+		t.Fatalf("had only %d requests in flight, the requests did not overlap", max)
+	}
+}
+
+func TestMaxConcurrentRequestsWaitRespectsContext(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v3/gists/blocked") {
+			close(blocked)
+			<-release
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	putter := newPutter(t, srv, putingh.WithMaxConcurrentRequests(1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		putter.GetBytes(context.Background(), "gist://"+owner+"/blocked/name.txt")
+	}()
+	// the blocked request holds the only slot
+	<-blocked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := putter.GetBytes(ctx, "gist://"+owner+"/other/name.txt")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the wait to end with the context", err)
+	}
+	close(release)
+	<-done
+}
+
+func TestMaxConcurrentRequestsHoldsSlotUntilBodyClosed(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "asset://" + owner + "/repo/v1/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited := newPutter(t, srv, putingh.WithMaxConcurrentRequests(1))
+	r, err := limited.GetFrom(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unread download still holds the only slot
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = limited.GetBytes(waitCtx, uri)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the wait to end with the context", err)
+	}
+
+	err = r.(io.Closer).Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := limited.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+}
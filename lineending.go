@@ -0,0 +1,114 @@
+package putingh
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path"
+)
+
+// LineEnding is the line ending text content is normalized to.
+type LineEnding int
+
+const (
+	LineEndingAsIs LineEnding = iota
+	LineEndingLF
+	LineEndingCRLF
+)
+
+// binarySniffSize is how much content is checked for a NUL byte, as git does.
+const binarySniffSize = 8000
+
+// WithLineEnding normalizes the line endings of text written to the file name,
+// content with a NUL byte in its first 8000 bytes is binary and kept as is.
+func WithLineEnding(fn func(name string) LineEnding) Option {
+	return func(p *PutInGH) {
+		p.lineEnding = fn
+	}
+}
+
+// WithLineEndingOnRead also normalizes the line endings of content read by GetFrom.
+func WithLineEndingOnRead(onRead bool) Option {
+	return func(p *PutInGH) {
+		p.lineEndingOnRead = onRead
+	}
+}
+
+func (s *PutInGH) lineEndingOf(name string) LineEnding {
+	if s.lineEnding == nil {
+		return LineEndingAsIs
+	}
+	return s.lineEnding(path.Base(name))
+}
+
+// normalizeLineEnding converts the line endings of r to mode unless r is binary.
+func normalizeLineEnding(r io.Reader, mode LineEnding) io.Reader {
+	if mode != LineEndingLF && mode != LineEndingCRLF {
+		return r
+	}
+	br := bufio.NewReaderSize(r, binarySniffSize)
+	head, _ := br.Peek(binarySniffSize)
+	if bytes.IndexByte(head, 0) >= 0 {
+		return br
+	}
+	return &lineEndingReader{
+		r:    br,
+		crlf: mode == LineEndingCRLF,
+	}
+}
+
+type lineEndingReader struct {
+	r    *bufio.Reader
+	crlf bool
+	buf  []byte
+	cr   bool
+	err  error
+}
+
+func (l *lineEndingReader) Read(p []byte) (int, error) {
+	for len(l.buf) == 0 {
+		if l.err != nil {
+			if l.cr {
+				// a lone CR at the end
+				l.cr = false
+				l.buf = append(l.buf, '\r')
+				break
+			}
+			return 0, l.err
+		}
+		chunk := make([]byte, 4096)
+		n, err := l.r.Read(chunk)
+		l.err = err
+		l.buf = l.convert(l.buf[:0], chunk[:n])
+	}
+	n := copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+// convert appends src with its line endings converted to dst,
+// a CR at the end of src is held back until it is known whether LF follows.
+func (l *lineEndingReader) convert(dst, src []byte) []byte {
+	for _, c := range src {
+		if l.cr {
+			l.cr = false
+			if c == '\n' {
+				if l.crlf {
+					dst = append(dst, '\r')
+				}
+				dst = append(dst, '\n')
+				continue
+			}
+			dst = append(dst, '\r')
+		}
+		switch {
+		case c == '\r':
+			l.cr = true
+		case c == '\n' && l.crlf:
+			dst = append(dst, '\r', '\n')
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,81 @@
+package putingh_test
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func lineEndingByExt(name string) putingh.LineEnding {
+	switch path.Ext(name) {
+	case ".txt":
+		return putingh.LineEndingLF
+	case ".bat":
+		return putingh.LineEndingCRLF
+	}
+	return putingh.LineEndingAsIs
+}
+
+func TestLineEnding(t *testing.T) {
+	mixed := "a\r\nb\nc\rd\r\n"
+	// CRLF straddles the chunks the content is converted in
+	long := strings.Repeat("0123456789abcde\r\n", 1<<10)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"lf.txt", mixed, "a\nb\nc\rd\n"},
+		{"crlf.bat", mixed, "a\r\nb\r\nc\rd\r\n"},
+		{"asis.md", mixed, mixed},
+		{"long.txt", long, strings.ReplaceAll(long, "\r\n", "\n")},
+		{"long.bat", strings.ReplaceAll(long, "\r\n", "\n"), long},
+		{"binary.txt", "\x00binary\r\n", "\x00binary\r\n"},
+		{"cr.bat", "ends with\r", "ends with\r"},
+	}
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithLineEnding(lineEndingByExt))
+	ctx := context.Background()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uri := "git://" + owner + "/repo/main/" + test.name
+			_, err := putter.PutIn(ctx, uri, strings.NewReader(test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := putter.GetBytes(ctx, uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLineEndingOnRead(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "gist://" + owner + "/*/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("a\r\nb\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for want, opts := range map[string][]putingh.Option{
+		"a\r\nb\r\n": {putingh.WithLineEnding(lineEndingByExt)},
+		"a\nb\n":     {putingh.WithLineEnding(lineEndingByExt), putingh.WithLineEndingOnRead(true)},
+	} {
+		got, err := newPutter(t, srv, opts...).GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
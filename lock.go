@@ -0,0 +1,70 @@
+package putingh
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// WithWorktreeLock also takes an OS file lock next to each worktree while it is used,
+// so processes sharing the tmp dir do not run over each other.
+// It only excludes other processes where flock is supported.
+func WithWorktreeLock(lock bool) Option {
+	return func(p *PutInGH) {
+		p.worktreeLock = lock
+	}
+}
+
+// lockWorktree serializes the use of the worktree of branch and returns the function releasing it.
+func (s *PutInGH) lockWorktree(ctx context.Context, owner, repo, branch string) (func(), error) {
+	dir, err := safeJoin(filepath.Join(s.tmpDir, "git"), owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	return s.lockDir(ctx, dir, dir+".lock")
+}
+
+// lockBareRepo serializes the writes to the repository set with WithBareRepoPath.
+func (s *PutInGH) lockBareRepo(ctx context.Context) (func(), error) {
+	return s.lockDir(ctx, s.bareRepoPath, filepath.Join(s.bareRepoPath, "putingh.lock"))
+}
+
+// lockDir takes the in-process lock of dir, and the file lock lockName when WithWorktreeLock is set.
+// Waiting for either gives up with the error of ctx once it is done.
+func (s *PutInGH) lockDir(ctx context.Context, dir, lockName string) (func(), error) {
+	m, _ := s.worktreeMuts.LoadOrStore(dir, make(chan struct{}, 1))
+	mut := m.(chan struct{})
+	select {
+	case mut <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	unlock := func() {
+		<-mut
+	}
+	if !s.worktreeLock {
+		return unlock, nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(lockName), 0755)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	f, err := os.OpenFile(lockName, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	err = lockFile(ctx, f)
+	if err != nil {
+		f.Close()
+		unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+		unlock()
+	}, nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package putingh
+
+import (
+	"context"
+	"os"
+)
+
+// lockFile is a no-op, only the in-process lock applies.
+func lockFile(ctx context.Context, f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
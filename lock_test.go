@@ -0,0 +1,94 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestWorktreeLockHonorsContext(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stall the next fetch, it holds the worktree lock meanwhile
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info/refs") {
+			once.Do(func() {
+				close(entered)
+				<-release
+			})
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	done := make(chan error, 1)
+	go func() {
+		_, err := putter.PutIn(ctx, uri, strings.NewReader("v2"))
+		done <- err
+	}()
+	<-entered
+
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = putter.PutIn(short, uri, strings.NewReader("v3"))
+	close(release)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v while the worktree is in use, want context.DeadlineExceeded", err)
+	}
+	err = <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBareRepoConcurrentPuts(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	dir := t.TempDir()
+	_, err := gogit.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putter := newPutter(t, srv, putingh.WithBareRepoPath(dir))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			uri := fmt.Sprintf("git://%s/repo/branch-%d/name.txt", owner, i)
+			_, errs[i] = putter.PutIn(ctx, uri, strings.NewReader(fmt.Sprint(i)))
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := putter.GetBytes(ctx, fmt.Sprintf("git://%s/repo/branch-%d/name.txt", owner, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != fmt.Sprint(i) {
+			t.Fatalf("branch-%d holds %q", i, got)
+		}
+	}
+}
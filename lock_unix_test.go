@@ -0,0 +1,57 @@
+//go:build unix
+
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestWorktreeLockExcludesOtherProcesses(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	tmp := t.TempDir()
+	putter := newPutter(t, srv, putingh.WithTmpDir(tmp), putingh.WithWorktreeLock(true))
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+
+	// hold the lock the way another process sharing tmp would
+	lockName := filepath.Join(tmp, "git", owner, "repo", "main.lock")
+	err := os.MkdirAll(filepath.Dir(lockName), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(lockName, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = putter.PutIn(short, uri, strings.NewReader("content"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v while the lock is held, want context.DeadlineExceeded", err)
+	}
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
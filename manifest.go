@@ -0,0 +1,64 @@
+package putingh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WithManifestWriter writes a "path  sha256  size" line to w for every file of a successful PutInGitDir,
+// with the SHA-256 of the content as in PutResult.
+func WithManifestWriter(w io.Writer) Option {
+	return func(p *PutInGH) {
+		p.manifestWriter = w
+	}
+}
+
+type manifestEntry struct {
+	name   string
+	sha256 string
+	size   int64
+}
+
+// newManifestReader hashes r while it is copied, the entry is complete at EOF.
+func newManifestReader(r io.Reader) *hashReader {
+	return &hashReader{
+		r:    r,
+		hash: sha256.New(),
+	}
+}
+
+func (h *hashReader) manifestEntry(name string) manifestEntry {
+	return manifestEntry{
+		name:   name,
+		sha256: hex.EncodeToString(h.hash.Sum(nil)),
+		size:   h.size,
+	}
+}
+
+// manifestEntryOfFile hashes a local file that is not copied, such as one skipped as unchanged.
+func manifestEntryOfFile(name, fname string) (manifestEntry, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return manifestEntry{}, err
+	}
+	defer f.Close()
+	hr := newManifestReader(f)
+	_, err = io.Copy(io.Discard, hr)
+	if err != nil {
+		return manifestEntry{}, err
+	}
+	return hr.manifestEntry(name), nil
+}
+
+func (s *PutInGH) writeManifest(entries []manifestEntry) error {
+	for _, entry := range entries {
+		_, err := fmt.Fprintf(s.manifestWriter, "%s  %s  %d\n", entry.name, entry.sha256, entry.size)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+const maxContentSize = 8
+
+// oversized is over maxContentSize and does not tell its length, so it is only caught while read.
+func oversized() io.Reader {
+	return struct{ io.Reader }{strings.NewReader(strings.Repeat("x", maxContentSize+1))}
+}
+
+func TestMaxContentSizeGit(t *testing.T) {
+	tmp := t.TempDir()
+	srv, _ := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+	before := headCommit(t, srv, "repo", "main").Hash
+	putter := newPutter(t, srv, putingh.WithTmpDir(tmp), putingh.WithMaxContentSize(maxContentSize))
+	_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", oversized())
+	if !errors.Is(err, putingh.ErrContentTooLarge) {
+		t.Fatalf("got %v, want ErrContentTooLarge", err)
+	}
+	if after := headCommit(t, srv, "repo", "main").Hash; after != before {
+		t.Fatalf("pushed %s over the limit", after)
+	}
+	matches, err := filepath.Glob(filepath.Join(tmp, "git", owner, "repo", "main", "name.txt*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("left %v in the worktree", matches)
+	}
+}
+
+func TestMaxContentSizeGitBare(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	dir := t.TempDir()
+	_, err := gogit.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putter := newPutter(t, srv, putingh.WithBareRepoPath(dir), putingh.WithMaxContentSize(maxContentSize))
+	_, err = putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", oversized())
+	if !errors.Is(err, putingh.ErrContentTooLarge) {
+		t.Fatalf("got %v, want ErrContentTooLarge", err)
+	}
+	repository, err := gogit.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := repository.Storer.IterEncodedObjects(plumbing.BlobObject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = objects.ForEach(func(obj plumbing.EncodedObject) error {
+		return errors.New("stored blob " + obj.Hash().String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxContentSizeGist(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithMaxContentSize(maxContentSize))
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", oversized())
+	if !errors.Is(err, putingh.ErrContentTooLarge) {
+		t.Fatalf("got %v, want ErrContentTooLarge", err)
+	}
+	gists, err := putter.FindGists(ctx, owner, func(*ghv3.Gist) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gists) != 0 {
+		t.Fatalf("created %d gists over the limit", len(gists))
+	}
+}
+
+func TestMaxContentSizeAsset(t *testing.T) {
+	for name, r := range map[string]func() io.Reader{
+		"streamed": oversized,
+		"sized": func() io.Reader {
+			return strings.NewReader(strings.Repeat("x", maxContentSize+1))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tmp := t.TempDir()
+			srv, _ := putinghtest.NewServer(t)
+			putter := newPutter(t, srv, putingh.WithTmpDir(tmp), putingh.WithMaxContentSize(maxContentSize))
+			ctx := context.Background()
+			_, err := putter.PutIn(ctx, "asset://"+owner+"/repo/v1/name.bin", r())
+			if !errors.Is(err, putingh.ErrContentTooLarge) {
+				t.Fatalf("got %v, want ErrContentTooLarge", err)
+			}
+			assets, err := putter.ListReleaseAssets(ctx, owner, "repo", "v1")
+			if err == nil && len(assets) != 0 {
+				t.Fatalf("uploaded %d assets over the limit", len(assets))
+			}
+			_, err = os.Stat(filepath.Join(tmp, "asset", owner, "repo", "v1", "name.bin"))
+			if !os.IsNotExist(err) {
+				t.Fatalf("left the staged asset behind: %v", err)
+			}
+		})
+	}
+}
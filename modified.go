@@ -0,0 +1,117 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// GetFromIfModifiedSince returns the content of uri like GetFrom, or a nil reader and false
+// when it has not changed after since. Contents without a modification time are always returned.
+func (s *PutInGH) GetFromIfModifiedSince(ctx context.Context, uri string, since time.Time) (io.Reader, bool, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, false, err
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		gistURI, err := s.resolveGistURL(ctx, uri)
+		if err != nil {
+			return nil, false, err
+		}
+		return s.GetFromIfModifiedSince(ctx, gistURI, since)
+	}
+	err = s.checkURIAllowed(u)
+	if err != nil {
+		return nil, false, err
+	}
+	modified, ok, err := s.modifiedTime(ctx, uri, u)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok && !modified.After(since) {
+		return nil, false, nil
+	}
+	r, err := s.GetFrom(ctx, uri)
+	if err != nil {
+		return nil, false, err
+	}
+	return r, true, nil
+}
+
+// modifiedTime returns when the content behind u last changed, ok is false when that is not known.
+func (s *PutInGH) modifiedTime(ctx context.Context, uri string, u *url.URL) (time.Time, bool, error) {
+	ctx, cancel := s.schemeContext(ctx, u.Scheme)
+	defer cancel()
+	switch u.Scheme {
+	case "git":
+		repo, branch, name, ok := splitGitPath(u.Path)
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("%q not match git://owner/repository/branch/name", uri)
+		}
+		return s.gitModifiedTime(ctx, u.Host, repo, branch, name)
+	case "asset":
+		sl := strings.SplitN(u.Path, "/", 4)
+		if len(sl) != 4 {
+			return time.Time{}, false, fmt.Errorf("%q not match asset://owner/repository/release/name", uri)
+		}
+		release, err := s.getRelease(ctx, u.Host, sl[1], sl[2])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		asset, err := s.matchAsset(release.Assets, sl[3])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if asset.UpdatedAt == nil {
+			return time.Time{}, false, nil
+		}
+		return asset.UpdatedAt.Time, true, nil
+	case "gist":
+		sl := strings.SplitN(u.Path, "/", 3)
+		if len(sl) != 3 {
+			return time.Time{}, false, fmt.Errorf("%q not match gist://owner/gist_id/name", uri)
+		}
+		gist, err := s.findGist(ctx, u.Host, sl[1], sl[2])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if gist == nil {
+			return time.Time{}, false, ErrNotFound
+		}
+		if _, ok := gist.Files[ghv3.GistFilename(sl[2])]; !ok {
+			return time.Time{}, false, ErrNotFound
+		}
+		if gist.UpdatedAt == nil {
+			return time.Time{}, false, nil
+		}
+		return gist.UpdatedAt.Time, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// gitModifiedTime uses the committer date of the last commit that changed name.
+func (s *PutInGH) gitModifiedTime(ctx context.Context, owner, repo, branch, name string) (time.Time, bool, error) {
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer unlock()
+	_, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	commit, err := lastGitCommit(repository, name)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return commit.Committer.When, true, nil
+}
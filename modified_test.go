@@ -0,0 +1,93 @@
+package putingh_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGetFromIfModifiedSince(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	for _, uri := range []string{
+		"git://" + owner + "/repo/main/name.txt",
+		"gist://" + owner + "/*/name.txt",
+		"asset://" + owner + "/repo/v1/name.txt",
+	} {
+		t.Run(uri, func(t *testing.T) {
+			before := time.Now().Add(-time.Hour)
+			_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, modified, err := putter.GetFromIfModifiedSince(ctx, uri, before)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !modified || r == nil {
+				t.Fatal("got it unmodified since before it was put")
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != "content" {
+				t.Fatalf("got %q, want %q", got, "content")
+			}
+
+			r, modified, err = putter.GetFromIfModifiedSince(ctx, uri, time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if modified || r != nil {
+				t.Fatal("got it modified since after it was put")
+			}
+		})
+	}
+}
+
+func TestGetFromIfModifiedSinceGitPath(t *testing.T) {
+	// commit times are whole seconds, so the commits are dated apart
+	when := time.Now().Add(-time.Hour).Truncate(time.Second)
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithGitCommitOptions(func(owner, repo, branch, name, path string) *gogit.CommitOptions {
+		sig := &object.Signature{Name: "putingh", Email: "putingh@example.com", When: when}
+		return &gogit.CommitOptions{Author: sig, Committer: sig}
+	}))
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := when
+	when = when.Add(time.Minute)
+	_, err = putter.PutIn(ctx, "git://"+owner+"/repo/main/other.txt", strings.NewReader("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a later commit to another path does not modify name.txt
+	_, modified, err := putter.GetFromIfModifiedSince(ctx, uri, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified {
+		t.Fatal("got it modified by a commit to another path")
+	}
+	_, modified, err = putter.GetFromIfModifiedSince(ctx, "git://"+owner+"/repo/main/other.txt", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified {
+		t.Fatal("got other.txt unmodified since before its commit")
+	}
+}
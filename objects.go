@@ -0,0 +1,64 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// WithReadViaObjectStore reads git files from the fetched objects instead of a checked out worktree,
+// falling back to the worktree when the blob can not be resolved.
+func WithReadViaObjectStore(objectStore bool) Option {
+	return func(p *PutInGH) {
+		p.readViaObjectStore = objectStore
+	}
+}
+
+var errFallbackWorktree = fmt.Errorf("fallback to worktree")
+
+// getFromGitObjects streams name from the tip of branch without touching the worktree.
+func (s *PutInGH) getFromGitObjects(ctx context.Context, owner, repo, branch, name string) (io.Reader, error) {
+	if path.Clean("/" + name)[1:] != name {
+		// leave unusual paths to the checks of the worktree
+		return nil, errFallbackWorktree
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	_, repository, err := s.fetchGitWith(ctx, owner, repo, branch, false)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := repository.Storer.Reference(plumbing.NewRemoteReferenceName(s.gitRemoteName(branch), branch))
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repository.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(name)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	if file.Mode == filemode.Symlink && s.readSymlinkTargets {
+		// the target has to be resolved on disk
+		return nil, errFallbackWorktree
+	}
+	r, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	return newReaderWithAutoCloser(r), nil
+}
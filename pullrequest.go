@@ -0,0 +1,74 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithPullRequestFlow makes git puts push to a new putingh/<branch>-<timestamp> branch
+// and open a pull request against the branch, for branches that do not accept direct pushes.
+// The URL of the pull request is returned instead of the raw URL.
+func WithPullRequestFlow(enable bool) Option {
+	return func(p *PutInGH) {
+		p.pullRequestFlow = enable
+	}
+}
+
+// WithAutoMerge merges the pull requests opened by WithPullRequestFlow right away,
+// a pull request the repository does not let merge is left open.
+func WithAutoMerge(merge bool) Option {
+	return func(p *PutInGH) {
+		p.autoMerge = merge
+	}
+}
+
+func (s *PutInGH) putInGitPullRequest(ctx context.Context, owner, repo, branch, name string, r io.Reader) (string, plumbing.Hash, error) {
+	if isPullRef(branch) {
+		return "", plumbing.ZeroHash, fmt.Errorf("%s is read-only", gitRemoteRef(branch))
+	}
+	// no other put shares the worktree of the new branch, so it is not locked
+	head := fmt.Sprintf("putingh/%s-%d", branch, time.Now().UnixNano())
+	dir, repository, err := s.fetchGit(withBranchBase(ctx, branch), owner, repo, head)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	// the branch is never written again
+	defer os.RemoveAll(dir)
+	fname, err := s.writeGitFile(dir, name, r)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	changed, hash, err := s.commitGit(ctx, repository, owner, repo, head, name, fname, []string{name})
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if len(changed) == 0 {
+		// nothing was pushed, the branch already holds the content
+		return s.gitURL(owner, repo) + "/raw/" + branch + "/" + name, hash, nil
+	}
+
+	title, body, _ := strings.Cut(s.commitMessage(owner, repo, branch, name, fname), "\n")
+	pr, _, err := s.cliv3.PullRequests.Create(ctx, owner, repo, &ghv3.NewPullRequest{
+		Title: &title,
+		Body:  ghv3.String(strings.TrimSpace(body)),
+		Head:  &head,
+		Base:  &branch,
+	})
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("create pull request: %w", err)
+	}
+	if s.autoMerge {
+		_, _, err = s.cliv3.PullRequests.Merge(ctx, owner, repo, pr.GetNumber(), "", nil)
+		if err != nil {
+			fmt.Fprintf(s.out, "merge %s: %v\n", pr.GetHTMLURL(), err)
+		}
+	}
+	return pr.GetHTMLURL(), hash, nil
+}
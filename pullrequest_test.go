@@ -0,0 +1,107 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// remoteBranches lists the branches of repo on srv.
+func remoteBranches(t *testing.T, url string) []string {
+	t.Helper()
+	remote := gogit.NewRemote(memory.NewStorage(), &gogitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	refs, err := remote.List(&gogit.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var branches []string
+	for _, ref := range refs {
+		if ref.Name().IsBranch() {
+			branches = append(branches, ref.Name().Short())
+		}
+	}
+	return branches
+}
+
+func TestPullRequestFlow(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr := newPutter(t, srv, putingh.WithPullRequestFlow(true))
+	u, err := pr.PutIn(ctx, uri, strings.NewReader("v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(u, "/pull/") {
+		t.Fatalf("got %q, want a pull request URL", u)
+	}
+	var heads []string
+	for _, branch := range remoteBranches(t, srv.URL+"/"+owner+"/repo") {
+		if strings.HasPrefix(branch, "putingh/main-") {
+			heads = append(heads, branch)
+		}
+	}
+	if len(heads) != 1 {
+		t.Fatalf("got head branches %v, want one", heads)
+	}
+	got, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "v1" {
+		t.Fatalf("base branch holds %q before the merge, want %q", got, "v1")
+	}
+
+	merge := newPutter(t, srv, putingh.WithPullRequestFlow(true), putingh.WithAutoMerge(true))
+	_, err = merge.PutIn(ctx, uri, strings.NewReader("v3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "v3" {
+		t.Fatalf("base branch holds %q after the merge, want %q", got, "v3")
+	}
+}
+
+func TestPullRequestFlowUnchanged(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr := newPutter(t, srv, putingh.WithPullRequestFlow(true))
+	u, err := pr.PutIn(ctx, uri, strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != srv.URL+"/"+owner+"/repo/raw/main/name.txt" {
+		t.Fatalf("got %q, want the raw URL", u)
+	}
+
+	strict := newPutter(t, srv, putingh.WithPullRequestFlow(true), putingh.WithRequireChange(true))
+	_, err = strict.PutIn(ctx, uri, strings.NewReader("v1"))
+	if !errors.Is(err, putingh.ErrNoChange) {
+		t.Fatalf("got %v, want ErrNoChange", err)
+	}
+}
@@ -6,11 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
@@ -18,9 +24,9 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	gogithttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	ghv3 "github.com/google/go-github/v56/github"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/semaphore"
 )
 
 var (
@@ -30,13 +36,30 @@ var (
 		WithTmpDir("./tmp/"),
 		WithOutput(io.Discard),
 		WithPerPage(100),
+		WithReadSymlinkTargets(true),
+		WithCreateReleaseIfMissing(true),
+		WithAssetBufferSize(8 << 20),
 		WithContext(context.Background()),
 		WithGitCommitMessage(func(owner, repo, branch, name, path string) string {
 			return fmt.Sprintf("Automatic update %s", name)
 		}),
 	}
 
-	ErrNotFound = fmt.Errorf("not found")
+	ErrNotFound        = fmt.Errorf("not found")
+	ErrReleaseNotFound = fmt.Errorf("release %w", ErrNotFound)
+	ErrInvalidPath     = fmt.Errorf("invalid path")
+
+	ErrGistOwnerMismatch  = fmt.Errorf("gist belongs to another owner")
+	ErrConflict           = fmt.Errorf("conflict")
+	ErrContentTooLarge    = fmt.Errorf("content too large")
+	ErrUnauthorized       = fmt.Errorf("unauthorized")
+	ErrMissingScopes      = fmt.Errorf("token is missing scopes")
+	ErrMultipleMatches    = fmt.Errorf("multiple matches")
+	ErrReadOnly           = fmt.Errorf("read-only")
+	ErrForbidden          = fmt.Errorf("forbidden")
+	ErrChecksumMismatch   = fmt.Errorf("checksum mismatch")
+	ErrNoChange           = fmt.Errorf("no change")
+	ErrVisibilityMismatch = fmt.Errorf("visibility mismatch")
 
 	anyFile = "*"
 )
@@ -47,24 +70,58 @@ func NewPutInGH(token string, options ...Option) *PutInGH {
 	p := &PutInGH{
 		token: token,
 	}
+	p.registerBuiltinSchemes()
 
 	src := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	httpClient := oauth2.NewClient(p.ctx, src)
-	p.httpCli = httpClient
+	p.httpCli = &http.Client{
+		Transport: &oauth2.Transport{
+			Source: src,
+			// a transport set with WithHTTPClient may wrap this one, the raw downloads to other hosts
+			// still go through it and lose the token here
+			Base: &stripAuthTransport{base: http.DefaultTransport},
+		},
+	}
 
 	for _, opt := range DefaultOptions {
 		if opt != nil {
 			opt(p)
 		}
 	}
+	p.gitSignatureExplicit = false
 	for _, opt := range options {
 		if opt != nil {
 			opt(p)
 		}
 	}
-	p.cliv3 = ghv3.NewClient(httpClient)
+	if p.apiURL == "" && p.host != "https://github.com" {
+		p.apiURL = p.host + "/api/v3/"
+		p.uploadURL = p.host + "/api/uploads/"
+	}
+	if p.gitSignatureFromEnv && !p.gitSignatureExplicit {
+		p.gitCommitOption = signatureFromEnv(p.gitCommitOption)
+	}
+	if p.httpTimeout > 0 {
+		cli := *p.httpCli
+		cli.Timeout = p.httpTimeout
+		p.httpCli = &cli
+	}
+	p.downloadCli = p.newDownloadClient(src)
+	if len(p.tokens) > 1 {
+		cli := *p.httpCli
+		cli.Transport = p.withTokenTransport(cli.Transport)
+		p.httpCli = &cli
+	}
+	p.httpCli = p.withRetryTransport(p.withLimitTransport(p.withHeaderTransport(p.httpCli)))
+	p.downloadCli = p.withRetryTransport(p.withLimitTransport(p.withHeaderTransport(p.downloadCli)))
+	p.cliv3 = ghv3.NewClient(p.httpCli)
+	if p.apiURL != "" {
+		cli, err := p.cliv3.WithEnterpriseURLs(p.apiURL, p.uploadURL)
+		if err == nil {
+			p.cliv3 = cli
+		}
+	}
 	return p
 }
 
@@ -80,6 +137,24 @@ func WithGitCommitMessage(fn func(owner, repo, branch, name, path string) string
 	}
 }
 
+// WithGitCommitMessageParts builds the commit message from a subject and a body separated by a blank line.
+func WithGitCommitMessageParts(fn func(owner, repo, branch, name, path string) (subject, body string)) Option {
+	return WithGitCommitMessage(func(owner, repo, branch, name, path string) string {
+		subject, body := fn(owner, repo, branch, name, path)
+		if body == "" {
+			return subject
+		}
+		return subject + "\n\n" + body
+	})
+}
+
+// WithCommitTrailers appends the trailers returned by fn, such as Signed-off-by, to every commit message.
+func WithCommitTrailers(fn func(owner, repo, branch, name string) map[string]string) Option {
+	return func(p *PutInGH) {
+		p.commitTrailers = fn
+	}
+}
+
 func WithGitAuthorSignature(username, email string) Option {
 	return WithGitCommitOptions(func(owner, repo, branch, name, path string) *gogit.CommitOptions {
 		return &gogit.CommitOptions{
@@ -95,6 +170,57 @@ func WithGitAuthorSignature(username, email string) Option {
 func WithGitCommitOptions(fn func(owner, repo, branch, name, path string) (opt *gogit.CommitOptions)) Option {
 	return func(p *PutInGH) {
 		p.gitCommitOption = fn
+		p.gitSignatureExplicit = true
+	}
+}
+
+// WithGitSignatureFromEnv takes the commit author and committer from GIT_AUTHOR_NAME, GIT_AUTHOR_EMAIL,
+// GIT_COMMITTER_NAME and GIT_COMMITTER_EMAIL like git does, unless a signature is configured explicitly.
+func WithGitSignatureFromEnv(fromEnv bool) Option {
+	return func(p *PutInGH) {
+		p.gitSignatureFromEnv = fromEnv
+	}
+}
+
+func signatureFromEnv(fn func(owner, repo, branch, name, path string) *gogit.CommitOptions) func(owner, repo, branch, name, path string) *gogit.CommitOptions {
+	return func(owner, repo, branch, name, path string) *gogit.CommitOptions {
+		opt := &gogit.CommitOptions{}
+		if base := fn(owner, repo, branch, name, path); base != nil {
+			*opt = *base
+		}
+		signature := object.Signature{When: time.Now()}
+		if opt.Author != nil {
+			signature = *opt.Author
+		}
+		author := signature
+		author.Name = envOr("GIT_AUTHOR_NAME", author.Name)
+		author.Email = envOr("GIT_AUTHOR_EMAIL", author.Email)
+		opt.Author = &author
+
+		// the GIT_AUTHOR_* variables do not apply to the committer
+		committer := signature
+		if opt.Committer != nil {
+			committer = *opt.Committer
+		}
+		committer.Name = envOr("GIT_COMMITTER_NAME", committer.Name)
+		committer.Email = envOr("GIT_COMMITTER_EMAIL", committer.Email)
+		opt.Committer = &committer
+		return opt
+	}
+}
+
+func envOr(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return value
+}
+
+// WithCommitSigner signs git commits with fn, which gets the commit payload and returns an armored signature,
+// so keys can stay in an external KMS or HSM.
+func WithCommitSigner(fn func(data []byte) ([]byte, error)) Option {
+	return func(p *PutInGH) {
+		p.commitSigner = fn
 	}
 }
 
@@ -110,9 +236,19 @@ func WithOutput(out io.Writer) Option {
 	}
 }
 
+// WithHost sets the GitHub web host git and raw URLs are built on, such as https://github.example.com for GitHub Enterprise.
+// Without WithAPIURL, a host other than https://github.com also serves the API under /api/v3/ and /api/uploads/.
 func WithHost(host string) Option {
 	return func(p *PutInGH) {
-		p.host = host
+		p.host = strings.TrimSuffix(host, "/")
+	}
+}
+
+// WithAPIURL sets the GitHub API and upload endpoints, for GitHub Enterprise they end with /api/v3/ and /api/uploads/.
+func WithAPIURL(baseURL, uploadURL string) Option {
+	return func(p *PutInGH) {
+		p.apiURL = baseURL
+		p.uploadURL = uploadURL
 	}
 }
 
@@ -122,6 +258,113 @@ func WithPerPage(perPage int) Option {
 	}
 }
 
+// WithReadSymlinkTargets sets whether GetFromGit follows symlinks in the worktree,
+// when false the link text is returned instead.
+func WithReadSymlinkTargets(follow bool) Option {
+	return func(p *PutInGH) {
+		p.readSymlinkTargets = follow
+	}
+}
+
+// WithHTTPTimeout bounds every single HTTP request, each git fetch and push counts as one request.
+// It applies even without a context deadline, when both are set whichever expires first wins.
+func WithHTTPTimeout(d time.Duration) Option {
+	return func(p *PutInGH) {
+		p.httpTimeout = d
+	}
+}
+
+// WithReleaseTag maps a release to its tag, so a release created for an asset can get a display name
+// different from its tag. The release is used as the tag by default.
+func WithReleaseTag(fn func(release string) string) Option {
+	return func(p *PutInGH) {
+		p.releaseTag = fn
+	}
+}
+
+// WithCreateReleaseIfMissing sets whether putting an asset creates a missing release,
+// when false ErrReleaseNotFound is returned instead.
+func WithCreateReleaseIfMissing(create bool) Option {
+	return func(p *PutInGH) {
+		p.createReleaseIfMissing = create
+	}
+}
+
+// WithSkipTLSForRawDownloads disables certificate verification for raw content downloads only,
+// the API and git clients keep verifying. A transport set with WithHTTPClient is kept,
+// verification is only turned off when it is an *http.Transport.
+func WithSkipTLSForRawDownloads(skip bool) Option {
+	return func(p *PutInGH) {
+		p.skipTLSForRawDownloads = skip
+	}
+}
+
+// WithGistLanguage sets the language hint of gist files, fn returning empty leaves it to GitHub.
+func WithGistLanguage(fn func(name string) string) Option {
+	return func(p *PutInGH) {
+		p.gistLanguage = fn
+	}
+}
+
+// WithAutoRepairWorktree re-initializes a cached worktree that is locked or can not be opened,
+// for example after an interrupted fetch.
+func WithAutoRepairWorktree(repair bool) Option {
+	return func(p *PutInGH) {
+		p.autoRepairWorktree = repair
+	}
+}
+
+// WithFsyncTempFiles flushes the temp file of an asset to disk before it is uploaded.
+func WithFsyncTempFiles(fsync bool) Option {
+	return func(p *PutInGH) {
+		p.fsyncTempFiles = fsync
+	}
+}
+
+// WithGistDescription sets the description of gists created for the file name.
+func WithGistDescription(fn func(name string) string) Option {
+	return func(p *PutInGH) {
+		p.gistDescription = fn
+	}
+}
+
+// WithGistStreamThreshold streams gist files larger than size bytes from their raw URL
+// instead of copying the inline content, zero always uses the inline content.
+func WithGistStreamThreshold(size int) Option {
+	return func(p *PutInGH) {
+		p.gistStreamThreshold = size
+	}
+}
+
+// WithGistJoinDelimiter sets the bytes written between files by GetFromGistAll.
+func WithGistJoinDelimiter(delimiter []byte) Option {
+	return func(p *PutInGH) {
+		p.gistJoinDelimiter = delimiter
+	}
+}
+
+// WithAssetNameMatcher picks the release asset to read when none is called the requested name exactly.
+func WithAssetNameMatcher(fn func(name string) bool) Option {
+	return func(p *PutInGH) {
+		p.assetNameMatcher = fn
+	}
+}
+
+// WithFirstMatch reads the first matching release asset instead of failing with ErrMultipleMatches.
+func WithFirstMatch(first bool) Option {
+	return func(p *PutInGH) {
+		p.firstMatch = first
+	}
+}
+
+// WithMaxContentSize limits the bytes read from the source of every put,
+// exceeding it fails with ErrContentTooLarge instead of truncating.
+func WithMaxContentSize(size int64) Option {
+	return func(p *PutInGH) {
+		p.maxContentSize = size
+	}
+}
+
 func WithHTTPClient(fun func(cli *http.Client) *http.Client) Option {
 	return func(p *PutInGH) {
 		p.httpCli = fun(p.httpCli)
@@ -132,98 +375,250 @@ type PutInGH struct {
 	tmpDir           string
 	gitCommitMessage func(owner, repo, branch, name, path string) (msg string)
 	gitCommitOption  func(owner, repo, branch, name, path string) (opt *gogit.CommitOptions)
+	commitSigner     func(data []byte) ([]byte, error)
+	commitTrailers   func(owner, repo, branch, name string) map[string]string
 	ctx              context.Context
 	out              io.Writer
 	host             string
+	apiURL           string
+	uploadURL        string
 	perPage          int
 
-	token   string
-	httpCli *http.Client
-	cliv3   *ghv3.Client
+	readSymlinkTargets     bool
+	httpTimeout            time.Duration
+	timeout                time.Duration
+	schemeTimeouts         map[string]time.Duration
+	createReleaseIfMissing bool
+	releaseTag             func(release string) string
+	gitContentsAPI         bool
+	commitBranch           string
+	skipTLSForRawDownloads bool
+	gistLanguage           func(name string) string
+	gistDescription        func(name string) string
+	autoRepairWorktree     bool
+	readViaObjectStore     bool
+	gitSignatureFromEnv    bool
+	gitSignatureExplicit   bool
+	bareRepoPath           string
+	forcePush              bool
+	autoSquash             int
+	diskCacheDir           string
+	diskCacheTTL           time.Duration
+	diskCacheMaxBytes      int64
+	progress               func(name string, sent, total int64)
+	gistStreamThreshold    int
+	gistJoinDelimiter      []byte
+	gistAutoSplit          bool
+	gistBinaryEncoding     bool
+	assetNameMatcher       func(name string) bool
+	firstMatch             bool
+	readOnly               bool
+	skipUnchanged          bool
+	repoAllowlist          func(scheme, owner, repo string) bool
+	defaultBranch          string
+	branchPlaceholder      string
+	repoDefaultBranches    sync.Map
+	worktreeMuts           sync.Map
+	worktreeLock           bool
+	resolveLFS             bool
+	sparseCheckout         []string
+	verifyAssetChecksum    bool
+	requireChange          bool
+	gistOptimisticLock     bool
+	branchBase             func(owner, repo string) string
+	gitMaxCacheObjects     int
+	manifestWriter         io.Writer
+	pullRequestFlow        bool
+	autoMerge              bool
+	assetSkipIdentical     bool
+	verifyVisibility       Visibility
+	gitFetchDepth          int
+	assetStagingDir        string
+	assetStagingFunc       func(name string) (*os.File, func(), error)
+	requestHeader          http.Header
+	events                 chan<- Event
+	droppedEvents          atomic.Uint64
+	schemes                sync.Map
+	lineEnding             func(name string) LineEnding
+	lineEndingOnRead       bool
+	maxContentSize         int64
+	maxGetBytes            int64
+	assetBufferSize        int64
+	fsyncTempFiles         bool
+	retry                  *retrier
+	retryClassifier        func(resp *http.Response, err error) bool
+	requestLimit           *semaphore.Weighted
+	randSrc                rand.Source
+
+	login     string
+	loginMut  sync.Mutex
+	author    *object.Signature
+	authorMut sync.Mutex
+
+	token       string
+	tokens      []string
+	tokenNext   uint64
+	httpCli     *http.Client
+	downloadCli *http.Client
+	cliv3       *ghv3.Client
 }
 
 func (s *PutInGH) GetFrom(ctx context.Context, uri string) (io.Reader, error) {
+	if s.events == nil {
+		r, err := s.getFromURI(ctx, uri)
+		if err != nil {
+			return nil, uriError(uri, err)
+		}
+		return r, nil
+	}
+	start := time.Now()
+	r, err := s.getFromURI(ctx, uri)
+	if err != nil {
+		err = uriError(uri, err)
+		s.emitEvent(EventGet, uri, 0, start, err)
+		return nil, err
+	}
+	return s.newEventReader(r, uri, start), nil
+}
+
+// uriError annotates err with the uri it was returned for, errors.Is and errors.As still see through it.
+func uriError(uri string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("putingh %s: %w", uri, err)
+}
+
+func (s *PutInGH) getFromURI(ctx context.Context, uri string) (io.Reader, error) {
 	url, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
 	}
-	switch url.Scheme {
-	case "git":
-		sl := strings.SplitN(url.Path, "/", 4)
-		if len(sl) != 4 {
-			return nil, fmt.Errorf("%q not match git://owner/repository/branch/name", uri)
-		}
-		return s.GetFromGit(ctx, url.Host, sl[1], sl[2], sl[3])
-	case "asset":
-		sl := strings.SplitN(url.Path, "/", 4)
-		if len(sl) != 4 {
-			return nil, fmt.Errorf("%q not match asset://owner/repository/release/name", uri)
-		}
-		return s.GetFromReleasesAsset(ctx, url.Host, sl[1], sl[2], sl[3])
-	case "gist":
-		sl := strings.SplitN(url.Path, "/", 3)
-		if len(sl) != 3 {
-			return nil, fmt.Errorf("%q not match gist://owner/gist_id/name", uri)
-		}
-		return s.GetFromGist(ctx, url.Host, sl[1], sl[2])
+	err = s.checkURIAllowed(url)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, url.Scheme)
+	var r io.Reader
+	if s.diskCacheDir != "" {
+		r, err = s.getFromCache(ctx, uri, url)
+	} else {
+		r, err = s.getFrom(ctx, url)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	body := newReaderWithCancel(r, cancel)
+	if mode := s.lineEndingOf(url.Path); s.lineEndingOnRead && mode != LineEndingAsIs {
+		return &readCloser{
+			Reader:  normalizeLineEnding(body, mode),
+			closers: []io.Closer{closerFunc(func() error { return closeReader(body) })},
+		}, nil
 	}
-	return nil, fmt.Errorf("%q not support", uri)
+	return body, nil
+}
+
+func (s *PutInGH) getFrom(ctx context.Context, url *url.URL) (io.Reader, error) {
+	handler, err := s.schemeHandler(url)
+	if err != nil {
+		return nil, err
+	}
+	return handler.Get(ctx, url)
 }
 
 func (s *PutInGH) PutInWithFile(ctx context.Context, uri, filename string) (string, error) {
+	if s.events == nil {
+		raw, err := s.putInWithFile(ctx, uri, filename)
+		return raw, uriError(uri, err)
+	}
+	start := time.Now()
+	raw, err := s.putInWithFile(ctx, uri, filename)
+	err = uriError(uri, err)
+	var size int64
+	if fi, statErr := os.Stat(filename); statErr == nil {
+		size = fi.Size()
+	}
+	s.emitEvent(EventPut, uri, size, start, err)
+	return raw, err
+}
+
+func (s *PutInGH) putInWithFile(ctx context.Context, uri, filename string) (string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", err
+	}
 	u, err := url.Parse(uri)
 	if err != nil {
 		return "", err
 	}
-	switch u.Scheme {
-	case "git":
-		sl := strings.SplitN(u.Path, "/", 4)
-		if len(sl) != 4 {
-			return "", fmt.Errorf("%q not match git://owner/repository/branch/name", uri)
-		}
-		return s.putInGitWithFile(ctx, u.Host, sl[1], sl[2], sl[3], filename)
-	case "asset":
-		sl := strings.SplitN(u.Path, "/", 4)
-		if len(sl) != 4 {
-			return "", fmt.Errorf("%q not match asset://owner/repository/release/name", uri)
-		}
-		return s.putInReleasesAssetWithFile(ctx, u.Host, sl[1], sl[2], sl[3], filename)
-	case "gist":
-		sl := strings.SplitN(u.Path, "/", 3)
-		if len(sl) != 3 {
-			return "", fmt.Errorf("%q not match gist://owner/gist_id/name", uri)
+	err = s.checkURIAllowed(u)
+	if err != nil {
+		return "", err
+	}
+	if s.lineEndingOf(u.Path) != LineEndingAsIs {
+		f, err := os.Open(filename)
+		if err != nil {
+			return "", err
 		}
-		return s.putInGistWithFile(ctx, u.Host, sl[1], sl[2], filename)
+		defer f.Close()
+		return s.putIn(ctx, uri, f)
 	}
-	return "", fmt.Errorf("%q not support", uri)
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, u.Scheme)
+	defer cancel()
+	handler, err := s.schemeHandler(u)
+	if err != nil {
+		return "", err
+	}
+	if fp, ok := handler.(schemeFilePutter); ok {
+		return fp.PutFile(ctx, u, filename)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return handler.Put(ctx, u, f)
 }
 
 func (s *PutInGH) PutIn(ctx context.Context, uri string, r io.Reader) (string, error) {
+	if s.events == nil {
+		raw, err := s.putIn(ctx, uri, r)
+		return raw, uriError(uri, err)
+	}
+	start := time.Now()
+	cr := &countReader{r: r}
+	raw, err := s.putIn(ctx, uri, keepReaderLen(cr, r, func() { cr.n = 0 }))
+	err = uriError(uri, err)
+	s.emitEvent(EventPut, uri, cr.n, start, err)
+	return raw, err
+}
+
+func (s *PutInGH) putIn(ctx context.Context, uri string, r io.Reader) (string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", err
+	}
 	u, err := url.Parse(uri)
 	if err != nil {
 		return "", err
 	}
-	switch u.Scheme {
-	case "git":
-		sl := strings.SplitN(u.Path, "/", 4)
-		if len(sl) != 4 {
-			return "", fmt.Errorf("%q not match git://owner/repository/branch/name", uri)
-		}
-		return s.putInGit(ctx, u.Host, sl[1], sl[2], sl[3], r)
-	case "asset":
-		sl := strings.SplitN(u.Path, "/", 4)
-		if len(sl) != 4 {
-			return "", fmt.Errorf("%q not match asset://owner/repository/release/name", uri)
-		}
-		return s.putInReleasesAsset(ctx, u.Host, sl[1], sl[2], sl[3], r)
-	case "gist":
-		sl := strings.SplitN(u.Path, "/", 3)
-		if len(sl) != 3 {
-			return "", fmt.Errorf("%q not match gist://owner/gist_id/name", uri)
-		}
-		return s.putInGist(ctx, u.Host, sl[1], sl[2], r)
+	err = s.checkURIAllowed(u)
+	if err != nil {
+		return "", err
+	}
+	if mode := s.lineEndingOf(u.Path); mode != LineEndingAsIs {
+		r = normalizeLineEnding(r, mode)
+	}
+	r = digestReader(ctx, r)
+	ctx = s.operationContext(ctx)
+	ctx, cancel := s.schemeContext(ctx, u.Scheme)
+	defer cancel()
+	handler, err := s.schemeHandler(u)
+	if err != nil {
+		return "", err
 	}
-	return "", fmt.Errorf("%q not support", uri)
+	return handler.Put(ctx, u, r)
 }
 
 func (s *PutInGH) putInGistWithFile(ctx context.Context, owner, gistId, name string, filename string) (string, error) {
@@ -236,34 +631,41 @@ func (s *PutInGH) putInGistWithFile(ctx context.Context, owner, gistId, name str
 }
 
 func (s *PutInGH) GetFromGist(ctx context.Context, owner, gistId, name string) (io.Reader, error) {
-	var oriGist *ghv3.Gist
-	err := s.eachGist(ctx, owner, func(gists []*ghv3.Gist) bool {
-		for _, gist := range gists {
-			if gistId == anyFile {
-				_, ok := gist.Files[ghv3.GistFilename(name)]
-				if ok {
-					oriGist = gist
-					return false
-				}
-			} else if *gist.ID == gistId {
-				oriGist = gist
-				return false
-			}
-		}
-		return true
-	})
+	if err := s.checkAllowed("gist", owner, gistId); err != nil {
+		return nil, err
+	}
+	names := []string{name}
+	if s.gistAutoSplit {
+		names = append(names, name+gistManifestSuffix)
+	}
+	oriGist, err := s.findGist(ctx, owner, gistId, names...)
 	if err != nil {
 		return nil, err
 	}
 	if oriGist == nil {
 		return nil, ErrNotFound
 	}
+	var r io.Reader
 	file, ok := oriGist.Files[ghv3.GistFilename(name)]
-	if !ok {
+	if ok {
+		r, err = s.readGistFile(ctx, file)
+	} else if s.gistAutoSplit && isGistSplit(oriGist, name) {
+		r, err = s.getFromGistSplit(ctx, oriGist, name)
+	} else {
 		return nil, ErrNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeGistContent(r), nil
+}
 
-	if file.Content != nil {
+// readGistFile returns the inline content of file, or streams it from the raw URL
+// when the content is truncated or larger than the stream threshold.
+func (s *PutInGH) readGistFile(ctx context.Context, file ghv3.GistFile) (io.Reader, error) {
+	stream := s.gistStreamThreshold > 0 && file.GetSize() > s.gistStreamThreshold
+	truncated := file.Content != nil && file.GetSize() > len(*file.Content)
+	if file.Content != nil && !((stream || truncated) && file.RawURL != nil) {
 		return bytes.NewBufferString(*file.Content), nil
 	}
 
@@ -277,32 +679,39 @@ func (s *PutInGH) GetFromGist(ctx context.Context, owner, gistId, name string) (
 	return nil, ErrNotFound
 }
 
+// GetFromGistLines returns the lines [start, end] of a gist file, counted from 1.
+// Out of range indices are clamped, so an empty reader is returned when nothing is selected.
+func (s *PutInGH) GetFromGistLines(ctx context.Context, owner, gistId, name string, start, end int) (io.Reader, error) {
+	r, err := s.GetFromGist(ctx, owner, gistId, name)
+	if err != nil {
+		return nil, err
+	}
+	return newLineRangeReader(r, start, end), nil
+}
+
 func (s *PutInGH) putInGist(ctx context.Context, owner, gistId, name string, r io.Reader) (string, error) {
-	data, err := io.ReadAll(r)
+	data, err := io.ReadAll(s.limitReader(r))
 	if err != nil {
 		return "", err
 	}
-	dataContext := string(data)
+	dataContext := s.encodeGistContent(data)
+	recordStoredContent(ctx, []byte(dataContext))
 
-	var oriGist *ghv3.Gist
-	err = s.eachGist(ctx, owner, func(gists []*ghv3.Gist) bool {
-		for _, gist := range gists {
-			if gistId == anyFile {
-				_, ok := gist.Files[ghv3.GistFilename(name)]
-				if ok {
-					oriGist = gist
-					return false
-				}
-			} else if *gist.ID == gistId {
-				oriGist = gist
-				return false
-			}
-		}
-		return true
-	})
+	names := []string{name}
+	if s.gistAutoSplit {
+		names = append(names, name+gistManifestSuffix)
+	}
+	oriGist, err := s.findGist(ctx, owner, gistId, names...)
+	if err != nil {
+		return "", err
+	}
+	err = s.checkGistVisibility(oriGist)
 	if err != nil {
 		return "", err
 	}
+	if s.gistAutoSplit && (len(dataContext) > gistPartSize || isGistSplit(oriGist, name)) {
+		return s.putInGistSplit(ctx, oriGist, name, dataContext)
+	}
 
 	var raw string
 	if oriGist == nil {
@@ -310,108 +719,235 @@ func (s *PutInGH) putInGist(ctx context.Context, owner, gistId, name string, r i
 			Public: ghv3.Bool(true),
 			Files: map[ghv3.GistFilename]ghv3.GistFile{
 				ghv3.GistFilename(name): {
-					Content: &dataContext,
+					Content:  &dataContext,
+					Language: s.gistLanguageOf(name),
 				},
 			},
-			Description: &gistId,
+			Description: s.gistDescriptionOf(name),
 		})
 		if err != nil {
 			return "", err
 		}
 		raw = *gist.Files[ghv3.GistFilename(name)].RawURL
 	} else {
-		oriGist.Files = map[ghv3.GistFilename]ghv3.GistFile{
-			ghv3.GistFilename(name): {
-				Filename: &name,
-				Content:  &dataContext,
-			},
+		var expected string
+		if s.gistOptimisticLock {
+			expected, err = s.gistHead(ctx, *oriGist.ID)
+			if err != nil {
+				return "", err
+			}
+		}
+		if s.requireChange {
+			unchanged, err := s.gistFileUnchanged(ctx, oriGist, name, dataContext)
+			if err != nil {
+				return "", err
+			}
+			if unchanged {
+				return "", fmt.Errorf("%w: %s", ErrNoChange, name)
+			}
 		}
-		gist, _, err := s.cliv3.Gists.Edit(ctx, *oriGist.ID, oriGist)
+		if s.gistOptimisticLock {
+			err = s.checkGistHead(ctx, *oriGist.ID, expected)
+			if err != nil {
+				return "", err
+			}
+		}
+		// only the changed file is sent, GitHub keeps the files missing from the edit
+		gist, _, err := s.cliv3.Gists.Edit(ctx, *oriGist.ID, &ghv3.Gist{
+			Files: map[ghv3.GistFilename]ghv3.GistFile{
+				ghv3.GistFilename(name): {
+					Filename: &name,
+					Content:  &dataContext,
+					Language: s.gistLanguageOf(name),
+				},
+			},
+		})
 		if err != nil {
 			return "", err
 		}
+		if s.gistOptimisticLock {
+			err = s.checkGistParent(ctx, *oriGist.ID, name, expected)
+			if err != nil {
+				return "", err
+			}
+		}
 		raw = *gist.Files[ghv3.GistFilename(name)].RawURL
 	}
 	raw = strings.SplitN(raw, "/raw/", 2)[0] + "/raw/" + name
 	return raw, nil
 }
 
-func (s *PutInGH) GetFromReleasesAsset(ctx context.Context, owner, repo, release, name string) (io.Reader, error) {
-	respRelease, response, err := s.cliv3.Repositories.GetReleaseByTag(ctx, owner, repo, release)
-	if err != nil && response.StatusCode != http.StatusNotFound {
+// findGist returns the gist with gistId, or the first gist of owner containing one of names when gistId is "*".
+// A nil gist without error means it does not exist.
+func (s *PutInGH) findGist(ctx context.Context, owner, gistId string, names ...string) (*ghv3.Gist, error) {
+	if gistId != anyFile {
+		gist, response, err := s.cliv3.Gists.Get(ctx, gistId)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if login := gist.GetOwner().GetLogin(); owner != "" && login != "" && !strings.EqualFold(login, owner) {
+			return nil, fmt.Errorf("%w: gist %s is owned by %s, not %s", ErrGistOwnerMismatch, gistId, login, owner)
+		}
+		return gist, nil
+	}
+
+	if owner == "" {
+		login, err := s.WhoAmI(ctx)
+		if err != nil {
+			return nil, err
+		}
+		owner = login
+	}
+	var oriGist *ghv3.Gist
+	err := s.eachGist(ctx, owner, func(gists []*ghv3.Gist) bool {
+		for _, gist := range gists {
+			for _, name := range names {
+				_, ok := gist.Files[ghv3.GistFilename(name)]
+				if ok {
+					oriGist = gist
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
 		return nil, err
 	}
+	return oriGist, nil
+}
+
+func (s *PutInGH) gistDescriptionOf(name string) *string {
+	if s.gistDescription == nil {
+		return nil
+	}
+	description := s.gistDescription(name)
+	return &description
+}
 
-	var releaseID *int64
-	if respRelease != nil {
-		releaseID = respRelease.ID
+func (s *PutInGH) gistLanguageOf(name string) *string {
+	if s.gistLanguage == nil {
+		return nil
 	}
+	language := s.gistLanguage(name)
+	if language == "" {
+		return nil
+	}
+	return &language
+}
 
-	if releaseID == nil {
-		return nil, ErrNotFound
+func (s *PutInGH) GetFromReleasesAsset(ctx context.Context, owner, repo, release, name string) (io.Reader, error) {
+	if err := s.checkAllowed("asset", owner, repo); err != nil {
+		return nil, err
 	}
-	repositoryRelease, _, err := s.cliv3.Repositories.GetRelease(ctx, owner, repo, *releaseID)
+	repositoryRelease, err := s.getRelease(ctx, owner, repo, release)
 	if err != nil {
 		return nil, err
 	}
 
-	downloadURL := ""
-	for _, asset := range repositoryRelease.Assets {
-		if *asset.Name == name {
-			if asset.BrowserDownloadURL == nil {
-				return nil, ErrNotFound
-			}
-			downloadURL = *asset.BrowserDownloadURL
-
-		}
+	asset, err := s.matchAsset(repositoryRelease.Assets, name)
+	if err != nil {
+		return nil, err
 	}
-	if downloadURL == "" {
+	if asset.BrowserDownloadURL == nil {
 		return nil, ErrNotFound
 	}
+	downloadURL := *asset.BrowserDownloadURL
+
+	var checksum string
+	if s.verifyAssetChecksum {
+		checksum, err = s.assetChecksum(ctx, repositoryRelease.Assets, asset)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	resp, err := s.httpGet(ctx, downloadURL)
 	if err != nil {
 		return nil, err
 	}
-	return newReaderWithAutoCloser(resp.Body), nil
+	body := newReaderWithAutoCloser(resp.Body)
+	if checksum == "" {
+		return body, nil
+	}
+	return &readCloser{
+		Reader:  newChecksumReader(body, asset.GetName(), checksum),
+		closers: []io.Closer{closerFunc(func() error { return closeReader(body) })},
+	}, nil
 }
 
-func (s *PutInGH) putInReleasesAssetWithFile(ctx context.Context, owner, repo, release, name string, filename string) (string, error) {
-	respRelease, response, err := s.cliv3.Repositories.GetReleaseByTag(ctx, owner, repo, release)
-	if err != nil && response.StatusCode != http.StatusNotFound {
-		return "", err
+// matchAsset returns the asset called name, or else the single asset matching name as a path.Match pattern
+// or the matcher set with WithAssetNameMatcher.
+func (s *PutInGH) matchAsset(assets []*ghv3.ReleaseAsset, name string) (*ghv3.ReleaseAsset, error) {
+	for _, asset := range assets {
+		if asset.GetName() == name {
+			return asset, nil
+		}
 	}
 
-	var releaseID *int64
-	if respRelease != nil {
-		releaseID = respRelease.ID
+	isPattern := strings.ContainsAny(name, "*?[")
+	if !isPattern && s.assetNameMatcher == nil {
+		return nil, ErrNotFound
 	}
+	var matched []*ghv3.ReleaseAsset
+	for _, asset := range assets {
+		ok := false
+		if isPattern {
+			m, err := path.Match(name, asset.GetName())
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q", err, name)
+			}
+			ok = m
+		} else {
+			ok = s.assetNameMatcher(asset.GetName())
+		}
+		if ok {
+			matched = append(matched, asset)
+		}
+	}
+	switch {
+	case len(matched) == 0:
+		return nil, ErrNotFound
+	case len(matched) > 1 && !s.firstMatch:
+		names := make([]string, 0, len(matched))
+		for _, asset := range matched {
+			names = append(names, asset.GetName())
+		}
+		return nil, fmt.Errorf("%w: %q matches %s", ErrMultipleMatches, name, strings.Join(names, ", "))
+	}
+	return matched[0], nil
+}
 
-	if releaseID == nil {
-		repositoryRelease, _, err := s.cliv3.Repositories.CreateRelease(ctx, owner, repo, &ghv3.RepositoryRelease{
-			Name:    &release,
-			TagName: &release,
-			Draft:   new(bool),
-		})
+func (s *PutInGH) putInReleasesAssetWithFile(ctx context.Context, owner, repo, release, name string, filename string) (string, error) {
+	if s.maxContentSize > 0 {
+		fi, err := os.Stat(filename)
 		if err != nil {
 			return "", err
 		}
-		releaseID = repositoryRelease.ID
-	} else {
-		repositoryRelease, _, err := s.cliv3.Repositories.GetRelease(ctx, owner, repo, *releaseID)
+		if fi.Size() > s.maxContentSize {
+			return "", fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrContentTooLarge, filename, fi.Size(), s.maxContentSize)
+		}
+	}
+	releaseID, identical, err := s.prepareReleaseAsset(ctx, owner, repo, release, name, func() (io.Reader, int64, error) {
+		f, err := os.Open(filename)
 		if err != nil {
-			return "", err
+			return nil, 0, err
 		}
-
-		for _, asset := range repositoryRelease.Assets {
-			if *asset.Name == name {
-				_, err := s.cliv3.Repositories.DeleteReleaseAsset(ctx, owner, repo, *asset.ID)
-				if err != nil {
-					return "", err
-				}
-				break
-			}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
 		}
+		return f, fi.Size(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if identical != nil {
+		return identical.GetBrowserDownloadURL(), nil
 	}
 
 	f, err := os.Open(filename)
@@ -420,37 +956,294 @@ func (s *PutInGH) putInReleasesAssetWithFile(ctx context.Context, owner, repo, r
 	}
 	defer f.Close()
 
-	respAsset, _, err := s.cliv3.Repositories.UploadReleaseAsset(ctx, owner, repo, *releaseID, &ghv3.UploadOptions{
-		Name: name,
-	}, f)
+	respAsset, err := s.uploadReleaseAsset(ctx, owner, repo, releaseID, name, f)
 	if err != nil {
 		return "", err
 	}
 	return *respAsset.BrowserDownloadURL, nil
 }
 
+// prepareReleaseAsset returns the id of the release to upload name to, creating the release if allowed
+// and deleting the asset it replaces. With WithRequireChange it fails when the asset already holds the content of open,
+// with WithAssetSkipIdentical that asset is returned and nothing is to be uploaded.
+func (s *PutInGH) prepareReleaseAsset(ctx context.Context, owner, repo, release, name string, open func() (io.Reader, int64, error)) (int64, *ghv3.ReleaseAsset, error) {
+	err := s.checkRepoVisibility(ctx, owner, repo)
+	if err != nil {
+		return 0, nil, err
+	}
+	repositoryRelease, created, err := s.getOrCreateRelease(ctx, owner, repo, release)
+	if err != nil {
+		return 0, nil, err
+	}
+	if created {
+		return repositoryRelease.GetID(), nil, nil
+	}
+	for _, asset := range repositoryRelease.Assets {
+		if *asset.Name == name {
+			if s.requireChange {
+				unchanged, err := s.assetUnchanged(ctx, asset, open)
+				if err != nil {
+					return 0, nil, err
+				}
+				if unchanged {
+					return 0, nil, fmt.Errorf("%w: %s", ErrNoChange, name)
+				}
+			}
+			if s.assetSkipIdentical {
+				identical, err := s.assetIdentical(ctx, repositoryRelease.Assets, asset, open)
+				if err != nil {
+					return 0, nil, err
+				}
+				if identical {
+					return repositoryRelease.GetID(), asset, nil
+				}
+			}
+			_, err := s.cliv3.Repositories.DeleteReleaseAsset(ctx, owner, repo, *asset.ID)
+			if err != nil {
+				return 0, nil, err
+			}
+			break
+		}
+	}
+	return repositoryRelease.GetID(), nil, nil
+}
+
+// GetOrCreateRelease returns the ID and the asset upload URL, without the {?name,label} template, of release.
+// The release is created when it does not exist and WithCreateReleaseIfMissing is set.
+func (s *PutInGH) GetOrCreateRelease(ctx context.Context, owner, repo, release string) (id int64, uploadURL string, err error) {
+	if err := s.checkAllowed("asset", owner, repo); err != nil {
+		return 0, "", err
+	}
+	ctx = s.operationContext(ctx)
+	repositoryRelease, _, err := s.getOrCreateRelease(ctx, owner, repo, release)
+	if err != nil {
+		return 0, "", err
+	}
+	uploadURL = repositoryRelease.GetUploadURL()
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	return repositoryRelease.GetID(), uploadURL, nil
+}
+
+// getOrCreateRelease resolves release, or creates it when allowed, created reports which one happened.
+func (s *PutInGH) getOrCreateRelease(ctx context.Context, owner, repo, release string) (_ *ghv3.RepositoryRelease, created bool, err error) {
+	repositoryRelease, err := s.getRelease(ctx, owner, repo, release)
+	if err == nil {
+		return repositoryRelease, false, nil
+	}
+	if !errors.Is(err, ErrReleaseNotFound) {
+		return nil, false, err
+	}
+	if !s.createReleaseIfMissing || isReleaseAlias(release) {
+		return nil, false, fmt.Errorf("%w: %s", ErrReleaseNotFound, release)
+	}
+	if err := s.checkWritable(); err != nil {
+		return nil, false, err
+	}
+	err = s.checkRepoVisibility(ctx, owner, repo)
+	if err != nil {
+		return nil, false, err
+	}
+	repositoryRelease, _, err = s.cliv3.Repositories.CreateRelease(ctx, owner, repo, &ghv3.RepositoryRelease{
+		Name:    &release,
+		TagName: ghv3.String(s.releaseTagOf(release)),
+		Draft:   new(bool),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return repositoryRelease, true, nil
+}
+
+// releaseTagOf returns the tag of the release named release.
+func (s *PutInGH) releaseTagOf(release string) string {
+	if s.releaseTag == nil || isReleaseAlias(release) {
+		return release
+	}
+	return s.releaseTag(release)
+}
+
+func isReleaseID(release string) bool {
+	return strings.HasPrefix(release, "@")
+}
+
+const (
+	// releaseLatest resolves to the release tagged latest, or else the latest release.
+	releaseLatest = "latest"
+	// prereleaseLatest resolves to the newest prerelease.
+	prereleaseLatest = "prerelease-latest"
+)
+
+// isReleaseAlias reports whether release is resolved rather than naming a tag.
+func isReleaseAlias(release string) bool {
+	return isReleaseID(release) || release == releaseLatest || release == prereleaseLatest
+}
+
+// getRelease resolves release by tag name, or by numeric ID when written as @<id>.
+func (s *PutInGH) getRelease(ctx context.Context, owner, repo, release string) (*ghv3.RepositoryRelease, error) {
+	var (
+		repositoryRelease *ghv3.RepositoryRelease
+		response          *ghv3.Response
+		err               error
+	)
+	if isReleaseID(release) {
+		id, perr := strconv.ParseInt(release[1:], 10, 64)
+		if perr != nil {
+			return nil, fmt.Errorf("%q is not a release id: %w", release, perr)
+		}
+		repositoryRelease, response, err = s.cliv3.Repositories.GetRelease(ctx, owner, repo, id)
+	} else if release == prereleaseLatest {
+		return s.getLatestPrerelease(ctx, owner, repo)
+	} else {
+		repositoryRelease, response, err = s.cliv3.Repositories.GetReleaseByTag(ctx, owner, repo, s.releaseTagOf(release))
+		if release == releaseLatest && response != nil && response.StatusCode == http.StatusNotFound {
+			// no release is tagged latest, use the latest one
+			repositoryRelease, response, err = s.cliv3.Repositories.GetLatestRelease(ctx, owner, repo)
+		}
+	}
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrReleaseNotFound, release)
+		}
+		return nil, err
+	}
+	return repositoryRelease, nil
+}
+
+// getLatestPrerelease returns the newest published prerelease.
+func (s *PutInGH) getLatestPrerelease(ctx context.Context, owner, repo string) (*ghv3.RepositoryRelease, error) {
+	var latest *ghv3.RepositoryRelease
+	err := s.eachReleases(ctx, owner, repo, func(releases []*ghv3.RepositoryRelease) bool {
+		for _, release := range releases {
+			if !release.GetPrerelease() || release.GetDraft() {
+				continue
+			}
+			if latest == nil || releaseTime(release).After(releaseTime(latest)) {
+				latest = release
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w: %s", ErrReleaseNotFound, prereleaseLatest)
+	}
+	return latest, nil
+}
+
+func releaseTime(release *ghv3.RepositoryRelease) time.Time {
+	if release.PublishedAt != nil {
+		return release.PublishedAt.Time
+	}
+	return release.GetCreatedAt().Time
+}
+
 func (s *PutInGH) putInReleasesAsset(ctx context.Context, owner, repo, release, name string, r io.Reader) (string, error) {
-	filename := filepath.Join(s.tmpDir, "asset", owner, repo, release, name)
-	os.MkdirAll(filepath.Dir(filename), 0755)
-	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	u, ok, err := s.putInReleasesAssetDirect(ctx, owner, repo, release, name, r)
+	if ok || err != nil {
+		return u, err
+	}
+	fmt.Fprintf(s.out, "uploading %s through a temp file\n", name)
+
+	f, cleanup, err := s.stageAsset(owner, repo, release, name)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	filename := f.Name()
+	_, err = io.Copy(f, s.limitReader(r))
 	if err != nil {
+		f.Close()
 		return "", err
 	}
-	_, err = io.Copy(f, r)
+	if s.fsyncTempFiles {
+		err = f.Sync()
+		if err != nil {
+			f.Close()
+			return "", err
+		}
+	}
+	err = f.Close()
 	if err != nil {
 		return "", err
 	}
-	f.Close()
 	return s.putInReleasesAssetWithFile(ctx, owner, repo, release, name, filename)
 }
 
 func (s *PutInGH) GetFromGit(ctx context.Context, owner, repo, branch, name string) (io.Reader, error) {
-	dir, _, err := s.fetchGit(ctx, owner, repo, branch)
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return nil, err
+	}
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
 	if err != nil {
 		return nil, err
 	}
-	fname := filepath.Join(dir, name)
-	f, err := os.Open(fname)
+	if s.readViaObjectStore {
+		r, err := s.getFromGitObjects(ctx, owner, repo, branch, name)
+		if err == nil {
+			if s.resolveLFS {
+				return s.resolveLFSPointer(ctx, owner, repo, r)
+			}
+			return r, nil
+		}
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidPath) {
+			return nil, err
+		}
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.readGitFile(dir, name)
+	if err != nil && len(s.sparseCheckout) != 0 && errors.Is(err, os.ErrNotExist) {
+		r, err = readGitHeadFile(repository, name)
+	}
+	if err != nil || !s.resolveLFS {
+		return r, err
+	}
+	return s.resolveLFSPointer(ctx, owner, repo, r)
+}
+
+// readGitFile opens name in the worktree dir, without following symlinks out of it.
+func (s *PutInGH) readGitFile(dir, name string) (io.Reader, error) {
+	fname, err := safeJoin(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if !s.readSymlinkTargets {
+		fi, err := os.Lstat(fname)
+		if err != nil {
+			return nil, err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(fname)
+			if err != nil {
+				return nil, err
+			}
+			return bytes.NewBufferString(target), nil
+		}
+	}
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	real, err := filepath.EvalSymlinks(fname)
+	if err != nil {
+		return nil, err
+	}
+	if !withinDir(root, real) {
+		return nil, fmt.Errorf("%w: %q resolves outside of the worktree", ErrInvalidPath, name)
+	}
+	f, err := os.Open(real)
 	if err != nil {
 		return nil, err
 	}
@@ -467,81 +1260,400 @@ func (s *PutInGH) putInGitWithFile(ctx context.Context, owner, repo, branch, nam
 }
 
 func (s *PutInGH) putInGit(ctx context.Context, owner, repo, branch, name string, r io.Reader) (string, error) {
+	rawURL, _, err := s.putInGitCommit(ctx, owner, repo, branch, name, r)
+	return rawURL, err
+}
+
+// putInGitCommit is putInGit also returning the commit holding the content,
+// zero when the content was unchanged and the path does not know the tip of branch.
+func (s *PutInGH) putInGitCommit(ctx context.Context, owner, repo, branch, name string, r io.Reader) (string, plumbing.Hash, error) {
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if s.pullRequestFlow {
+		return s.putInGitPullRequest(ctx, owner, repo, branch, name, r)
+	}
+	if s.gitContentsAPI {
+		return s.putInGitContents(ctx, owner, repo, branch, name, r)
+	}
+	if s.bareRepoPath != "" {
+		return s.putInGitBare(ctx, owner, repo, branch, name, r)
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	defer unlock()
 	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
 	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	fname, err := s.writeGitFile(dir, name, r)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	_, hash, err := s.commitGit(ctx, repository, owner, repo, branch, name, fname, []string{name})
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	return s.gitURL(owner, repo) + "/raw/" + branch + "/" + name, hash, nil
+}
+
+// PutInGitIfMatch puts r in the git repository only if the current blob SHA of name equals expectedSHA,
+// an empty expectedSHA means the file must not exist yet. ErrConflict is returned otherwise.
+func (s *PutInGH) PutInGitIfMatch(ctx context.Context, owner, repo, branch, name, expectedSHA string, r io.Reader) (string, error) {
+	if err := s.checkWritable(); err != nil {
 		return "", err
 	}
-	fname := filepath.Join(dir, name)
-	err = os.MkdirAll(filepath.Dir(fname), 0755)
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return "", err
+	}
+	ctx = s.operationContext(ctx)
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
 	if err != nil {
 		return "", err
 	}
-	f, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
 	if err != nil {
 		return "", err
 	}
-	_, err = io.Copy(f, r)
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
 	if err != nil {
-		f.Close()
 		return "", err
 	}
-	f.Close()
+	fname, err := safeJoin(dir, name)
+	if err != nil {
+		return "", err
+	}
+	current, err := blobHashOfFile(fname)
+	if err != nil {
+		return "", err
+	}
+	if current != expectedSHA {
+		return "", fmt.Errorf("%w: %s is at %q, expected %q", ErrConflict, name, current, expectedSHA)
+	}
 
-	work, err := repository.Worktree()
+	_, err = s.writeGitFile(dir, name, r)
 	if err != nil {
 		return "", err
 	}
-	_, err = work.Add(name)
+	_, _, err = s.commitGit(ctx, repository, owner, repo, branch, name, fname, []string{name})
 	if err != nil {
-		return "", fmt.Errorf("git add: %w", err)
+		return "", err
 	}
-	status, err := work.Status()
+	return s.gitURL(owner, repo) + "/raw/" + branch + "/" + name, nil
+}
+
+// blobHashOfFile returns the git blob hash of the file, or empty if it does not exist.
+func blobHashOfFile(fname string) (string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	hasher := plumbing.NewHasher(plumbing.BlobObject, fi.Size())
+	_, err = io.Copy(hasher, f)
+	if err != nil {
+		return "", err
+	}
+	return hasher.Sum().String(), nil
+}
+
+func (s *PutInGH) writeGitFile(dir, name string, r io.Reader) (string, error) {
+	fname, err := safeJoinResolved(dir, name)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(fname), 0755)
+	if err != nil {
+		return "", err
+	}
+	// an existing file keeps its mode, such as the executable bit
+	mode := os.FileMode(0644)
+	if fi, err := os.Lstat(fname); err == nil && fi.Mode().IsRegular() {
+		mode = fi.Mode().Perm()
+	}
+	err = writeFileAtomic(fname, s.limitReader(r))
+	if err != nil {
+		return "", err
+	}
+	err = os.Chmod(fname, mode)
 	if err != nil {
 		return "", err
 	}
+	return fname, nil
+}
 
-	if len(status) != 0 &&
-		status[name] != nil &&
-		(status[name].Staging != gogit.Unmodified || status[name].Worktree != gogit.Unmodified) {
-		opt := s.gitCommitOption(owner, repo, branch, name, fname)
-		message := s.gitCommitMessage(owner, repo, branch, name, fname)
-		_, err = work.Commit(message, opt)
+// gitCommitOptions returns a copy of the options for a commit, the callback may return the same options every time.
+func (s *PutInGH) gitCommitOptions(owner, repo, branch, name, path string) gogit.CommitOptions {
+	var opt gogit.CommitOptions
+	if o := s.gitCommitOption(owner, repo, branch, name, path); o != nil {
+		opt = *o
+	}
+	return opt
+}
+
+func (s *PutInGH) commitGit(ctx context.Context, repository *gogit.Repository, owner, repo, branch, name, path string, names []string) ([]string, plumbing.Hash, error) {
+	if isPullRef(branch) {
+		return nil, plumbing.ZeroHash, fmt.Errorf("%s is read-only", gitRemoteRef(branch))
+	}
+	err := s.checkRepoVisibility(ctx, owner, repo)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	work, err := repository.Worktree()
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	for _, n := range names {
+		err = addGitFile(repository, work, n)
 		if err != nil {
-			return "", fmt.Errorf("git commit: %w", err)
+			return nil, plumbing.ZeroHash, fmt.Errorf("git add: %w", err)
+		}
+	}
+	status, err := work.Status()
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	changed := []string{}
+	for _, n := range names {
+		if status[n] != nil &&
+			(status[n].Staging != gogit.Unmodified || status[n].Worktree != gogit.Unmodified) {
+			changed = append(changed, n)
+		}
+	}
+	if len(changed) == 0 {
+		if s.requireChange {
+			return nil, plumbing.ZeroHash, fmt.Errorf("%w: %s", ErrNoChange, name)
 		}
-		err = repository.PushContext(ctx, &gogit.PushOptions{
-			Auth:       s.gitBasicAuth(owner),
+		return changed, gitBranchHead(repository, branch), nil
+	}
+
+	opt := s.gitCommitOptions(owner, repo, branch, name, path)
+	// there are changes, removing the last file leaves an empty tree
+	opt.AllowEmptyCommits = true
+	message := s.commitMessage(owner, repo, branch, name, path)
+	hash, err := work.Commit(message, &opt)
+	if err != nil {
+		return nil, plumbing.ZeroHash, fmt.Errorf("git commit: %w", err)
+	}
+	if s.commitSigner != nil {
+		_, err = s.signCommit(repository, branch, hash)
+		if err != nil {
+			return nil, plumbing.ZeroHash, fmt.Errorf("git sign: %w", err)
+		}
+	}
+	force, err := s.pushForce(repository, branch)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+	err = s.retryGit(ctx, func() error {
+		pushCtx, cancel := s.gitRequestContext(ctx)
+		defer cancel()
+		return repository.PushContext(pushCtx, &gogit.PushOptions{
+			Auth:       s.gitBasicAuth(ctx, owner),
 			RemoteName: s.gitRemoteName(branch),
 			Progress:   s.out,
+			Force:      force,
 		})
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			s.cleanupGit(work.Filesystem.Root())
+		}
+		if shallow, _ := repository.Storer.Shallow(); len(shallow) != 0 {
+			return nil, plumbing.ZeroHash, fmt.Errorf("git push from a shallow clone of depth %d: %w", s.gitFetchDepth, err)
+		}
+		return nil, plumbing.ZeroHash, fmt.Errorf("git push: %w", err)
+	}
+	return changed, gitBranchHead(repository, branch), nil
+}
+
+// gitBranchHead returns the tip of the local branch in repository, zero when it does not exist.
+func gitBranchHead(repository *gogit.Repository, branch string) plumbing.Hash {
+	ref, err := repository.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return ref.Hash()
+}
+
+// commitMessage returns the commit message followed by a blank line and the sorted trailers.
+func (s *PutInGH) commitMessage(owner, repo, branch, name, path string) string {
+	message := s.gitCommitMessage(owner, repo, branch, name, path)
+	if s.commitTrailers == nil {
+		return message
+	}
+	trailers := s.commitTrailers(owner, repo, branch, name)
+	if len(trailers) == 0 {
+		return message
+	}
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	buf.WriteString(strings.TrimRight(message, "\n"))
+	buf.WriteString("\n\n")
+	for _, key := range keys {
+		buf.WriteString(key)
+		buf.WriteString(": ")
+		buf.WriteString(trailers[key])
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// signCommit replaces the commit at the tip of branch with a copy signed by the commit signer.
+func (s *PutInGH) signCommit(repository *gogit.Repository, branch string, hash plumbing.Hash) (plumbing.Hash, error) {
+	commit, err := repository.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	err = s.sign(commit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	signed, err := storeCommit(repository, commit)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	err = repository.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), signed))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return signed, nil
+}
+
+// sign sets the signature of commit from the commit signer.
+func (s *PutInGH) sign(commit *object.Commit) error {
+	payload := &plumbing.MemoryObject{}
+	err := commit.EncodeWithoutSignature(payload)
+	if err != nil {
+		return err
+	}
+	reader, err := payload.Reader()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	signature, err := s.commitSigner(data)
+	if err != nil {
+		return err
+	}
+	commit.PGPSignature = string(signature)
+	return nil
+}
+
+func storeCommit(repository *gogit.Repository, commit *object.Commit) (plumbing.Hash, error) {
+	obj := repository.Storer.NewEncodedObject()
+	err := commit.Encode(obj)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repository.Storer.SetEncodedObject(obj)
+}
+
+// ReconcileGit makes the branch contain exactly the desired files and returns the affected paths.
+func (s *PutInGH) ReconcileGit(ctx context.Context, owner, repo, branch string, desired map[string]io.Reader) ([]string, error) {
+	return s.ReconcileGitWithPrefix(ctx, owner, repo, branch, "", desired)
+}
+
+// ReconcileGitWithPrefix is like ReconcileGit but only removes tracked files under prefix.
+// The prefix is a path, "docs" covers docs and docs/a.md but not docs2/a.md.
+func (s *PutInGH) ReconcileGitWithPrefix(ctx context.Context, owner, repo, branch, prefix string, desired map[string]io.Reader) ([]string, error) {
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return nil, err
+	}
+	_, changed, err := s.updateGit(ctx, owner, repo, branch, func(dir string, repository *gogit.Repository) (string, []string, error) {
+		names := make([]string, 0, len(desired))
+		for name := range desired {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			_, err := s.writeGitFile(dir, name, desired[name])
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		idx, err := repository.Storer.Index()
 		if err != nil {
-			return "", fmt.Errorf("git push: %w", err)
+			return "", nil, err
+		}
+		for _, entry := range idx.Entries {
+			if !underPrefix(entry.Name, prefix) {
+				continue
+			}
+			if _, ok := desired[entry.Name]; ok {
+				continue
+			}
+			fname, err := safeJoinResolved(dir, entry.Name)
+			if err != nil {
+				return "", nil, err
+			}
+			err = os.Remove(fname)
+			if err != nil && !os.IsNotExist(err) {
+				return "", nil, err
+			}
+			names = append(names, entry.Name)
 		}
+		return prefix, names, nil
+	})
+	return changed, err
+}
+
+// underPrefix reports whether name is prefix or inside it, an empty prefix covers every name.
+func underPrefix(name, prefix string) bool {
+	if prefix == "" {
+		return true
 	}
-	return s.gitURL(owner, repo) + "/raw/" + branch + "/" + name, nil
+	return name == prefix || strings.HasPrefix(name, strings.TrimSuffix(prefix, "/")+"/")
 }
 
 func (s *PutInGH) fetchGit(ctx context.Context, owner, repo, branch string) (string, *gogit.Repository, error) {
+	return s.fetchGitWith(ctx, owner, repo, branch, true)
+}
+
+// fetchGitWith fetches branch into the cached repository, and with checkout also resets the worktree to it.
+func (s *PutInGH) fetchGitWith(ctx context.Context, owner, repo, branch string, checkout bool) (string, *gogit.Repository, error) {
 	giturl := s.gitURL(owner, repo)
 
-	auth := s.gitBasicAuth(owner)
+	auth := s.gitBasicAuth(ctx, owner)
 
-	dir := filepath.Join(s.tmpDir, "git", owner, repo, branch)
+	dir, err := safeJoin(filepath.Join(s.tmpDir, "git"), owner, repo, branch)
+	if err != nil {
+		return "", nil, err
+	}
 	os.MkdirAll(filepath.Dir(dir), 0755)
 
 	remoteName := s.gitRemoteName(branch)
 	refName := plumbing.NewBranchReferenceName(branch)
+	remoteRefName := plumbing.NewRemoteReferenceName(remoteName, branch)
 	fetch := []gogitconfig.RefSpec{
-		gogitconfig.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%[1]s", branch, remoteName)),
+		gogitconfig.RefSpec(fmt.Sprintf("+%s:%s", gitRemoteRef(branch), remoteRefName)),
 	}
 
-	var repository *gogit.Repository
-	_, err := os.Stat(dir + "/.git")
-	if err == nil {
-		repository, err = gogit.PlainOpen(dir)
-	} else {
-		repository, err = gogit.PlainInit(dir, false)
-	}
+	repository, err := s.openGit(dir)
 	if err != nil {
 		return "", nil, fmt.Errorf("%w: %s", err, dir)
 	}
@@ -586,28 +1698,42 @@ func (s *PutInGH) fetchGit(ctx context.Context, owner, repo, branch string) (str
 		}
 	}
 
-	err = remote.FetchContext(ctx, &gogit.FetchOptions{
-		RemoteName: remoteName,
-		RefSpecs:   fetch,
-		Progress:   s.out,
-		Auth:       auth,
+	err = s.retryGit(ctx, func() error {
+		fetchCtx, cancel := s.gitRequestContext(ctx)
+		defer cancel()
+		return remote.FetchContext(fetchCtx, &gogit.FetchOptions{
+			RemoteName: remoteName,
+			RefSpecs:   fetch,
+			Depth:      s.gitFetchDepth,
+			Progress:   s.out,
+			Auth:       auth,
+		})
 	})
 	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
 		var noMatchingRefSpecError gogit.NoMatchingRefSpecError
 		if !errors.As(err, &noMatchingRefSpecError) {
+			if ctx.Err() != nil {
+				s.cleanupGit(dir)
+			}
 			return "", nil, fmt.Errorf("git fetch: %w", err)
 		}
 	}
 
-	refIter, err := repository.Storer.IterReferences()
-	if err != nil {
-		return "", nil, fmt.Errorf("iterReferences: %w", err)
-	}
-	ref, err := refIter.Next()
+	ref, err := repository.Storer.Reference(remoteRefName)
 	if err != nil {
-		return "", nil, fmt.Errorf("next: %w", err)
-	}
-	if !ref.Hash().IsZero() {
+		if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return "", nil, fmt.Errorf("reference: %w", err)
+		}
+		if isPullRef(branch) {
+			return "", nil, fmt.Errorf("%w: %s", ErrNotFound, gitRemoteRef(branch))
+		}
+		if checkout {
+			err = s.checkoutBranchBase(ctx, repository, remote, owner, repo, branch)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+	} else if checkout && !ref.Hash().IsZero() {
 		err = repository.Storer.SetReference(plumbing.NewHashReference(refName, ref.Hash()))
 		if err != nil {
 			return "", nil, fmt.Errorf("setReference: %w", err)
@@ -617,27 +1743,88 @@ func (s *PutInGH) fetchGit(ctx context.Context, owner, repo, branch string) (str
 		if err != nil {
 			return "", nil, err
 		}
-		err = work.Reset(&gogit.ResetOptions{
-			Commit: ref.Hash(),
-			Mode:   gogit.HardReset,
-		})
+		err = s.resetGit(repository, work, ref.Hash())
 		if err != nil {
 			return "", nil, fmt.Errorf("git reset: %w", err)
 		}
 	}
 
+	s.gcGit(repository)
 	return dir, repository, nil
 }
 
-func (s *PutInGH) gitRemoteName(branch string) string {
-	return "origin-" + branch
+// openGit opens the worktree in dir or initializes it,
+// with auto repair a locked or unreadable worktree is re-initialized.
+func (s *PutInGH) openGit(dir string) (*gogit.Repository, error) {
+	_, err := os.Stat(dir + "/.git")
+	if err != nil {
+		return plainInitGit(dir)
+	}
+
+	if s.autoRepairWorktree {
+		_, err = os.Stat(filepath.Join(dir, ".git", "index.lock"))
+		if err == nil {
+			return s.repairGit(dir, fmt.Errorf("stale index.lock"))
+		}
+	}
+
+	repository, err := plainOpenGit(dir)
+	if err != nil {
+		if s.autoRepairWorktree {
+			return s.repairGit(dir, err)
+		}
+		return nil, err
+	}
+	return repository, nil
 }
 
-func (s *PutInGH) gitBasicAuth(owner string) *gogithttp.BasicAuth {
-	return &gogithttp.BasicAuth{
-		Username: owner,
-		Password: s.token,
+func (s *PutInGH) repairGit(dir string, reason error) (*gogit.Repository, error) {
+	fmt.Fprintf(s.out, "repairing worktree %s: %v\n", dir, reason)
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return nil, err
 	}
+	return plainInitGit(dir)
+}
+
+func (s *PutInGH) gitRequestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.httpTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.httpTimeout)
+}
+
+// splitGitPath splits /repository/branch/name, where branch may be pull/<number>.
+func splitGitPath(path string) (repo, branch, name string, ok bool) {
+	sl := strings.SplitN(path, "/", 4)
+	if len(sl) != 4 {
+		return "", "", "", false
+	}
+	repo, branch, name = sl[1], sl[2], sl[3]
+	if branch == "pull" {
+		sl = strings.SplitN(name, "/", 2)
+		if len(sl) != 2 {
+			return "", "", "", false
+		}
+		branch, name = "pull/"+sl[0], sl[1]
+	}
+	return repo, branch, name, true
+}
+
+func isPullRef(branch string) bool {
+	return strings.HasPrefix(branch, "pull/")
+}
+
+// gitRemoteRef returns the remote reference of branch, pull/<number> maps to the pull request head.
+func gitRemoteRef(branch string) string {
+	if isPullRef(branch) {
+		return "refs/" + branch + "/head"
+	}
+	return "refs/heads/" + branch
+}
+
+func (s *PutInGH) gitRemoteName(branch string) string {
+	return "origin-" + branch
 }
 
 func (s *PutInGH) gitURL(owner, repo string) string {
@@ -649,7 +1836,16 @@ func (s *PutInGH) httpGet(ctx context.Context, uri string) (*http.Response, erro
 	if err != nil {
 		return nil, err
 	}
-	return s.httpCli.Do(req)
+	resp, err := s.downloadCli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	err = decodeContentEncoding(resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (s *PutInGH) eachReleases(ctx context.Context, owner, repo string, next func([]*ghv3.RepositoryRelease) bool) error {
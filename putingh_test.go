@@ -0,0 +1,109 @@
+package putingh_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+const owner = putinghtest.Login
+
+// newPutter returns another PutInGH talking to srv with its own temp dir.
+func newPutter(t testing.TB, srv *httptest.Server, options ...putingh.Option) *putingh.PutInGH {
+	opts := []putingh.Option{
+		putingh.WithHost(srv.URL),
+		putingh.WithTmpDir(t.TempDir()),
+	}
+	return putingh.NewPutInGH("putinghtest-token", append(opts, options...)...)
+}
+
+// pushGit replaces branch of repo on srv with a single commit holding files.
+// A content starting with "symlink:" makes a symlink to the rest of it,
+// one starting with "exec:" makes an executable file of the rest of it.
+func pushGit(t testing.TB, srv *httptest.Server, repo, branch string, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	repository, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		fname := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(fname), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case strings.HasPrefix(content, "symlink:"):
+			err = os.Symlink(strings.TrimPrefix(content, "symlink:"), fname)
+		case strings.HasPrefix(content, "exec:"):
+			err = os.WriteFile(fname, []byte(strings.TrimPrefix(content, "exec:")), 0755)
+		default:
+			err = os.WriteFile(fname, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = work.Add(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = work.Commit("fixture", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "fixture", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repository.CreateRemote(&gogitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{srv.URL + "/" + owner + "/" + repo},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repository.Push(&gogit.PushOptions{
+		RefSpecs: []gogitconfig.RefSpec{gogitconfig.RefSpec("+refs/heads/master:refs/heads/" + branch)},
+		Force:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// headCommit clones branch of repo from srv and returns the commit at its tip.
+func headCommit(t testing.TB, srv *httptest.Server, repo, branch string) *object.Commit {
+	t.Helper()
+	repository, err := gogit.Clone(memory.NewStorage(), nil, &gogit.CloneOptions{
+		URL:           srv.URL + "/" + owner + "/" + repo,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return commit
+}
@@ -0,0 +1,101 @@
+package putinghtest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const codeloadPrefix = "/codeload/"
+
+// serveArchiveLink redirects repos/{owner}/{repo}/{tarball|zipball}[/{ref}] to the archive download.
+func (f *fake) serveArchiveLink(w http.ResponseWriter, r *http.Request, key, format, ref string) {
+	if ref == "" {
+		ref = DefaultBranch
+	}
+	ext := "tar.gz"
+	if format == "zipball" {
+		ext = "zip"
+	}
+	http.Redirect(w, r, f.url+codeloadPrefix+key+"/"+ext+"/"+ref, http.StatusFound)
+}
+
+// serveArchive writes the tree of a repository at a ref as a tar.gz or zip,
+// with every file under a {repo}-{ref} directory like codeload.github.com.
+func (f *fake) serveArchive(w http.ResponseWriter, r *http.Request, sl []string) {
+	if len(sl) != 4 {
+		notFound(w)
+		return
+	}
+	key, ext, ref := sl[0]+"/"+sl[1], sl[2], sl[3]
+	st, ok := f.repos[key]
+	if !ok {
+		notFound(w)
+		return
+	}
+	hash := plumbing.NewHash(ref)
+	if branch, err := st.Reference(plumbing.NewBranchReferenceName(ref)); err == nil {
+		hash = branch.Hash()
+	}
+	commit, err := object.GetCommit(st, hash)
+	if err != nil {
+		notFound(w)
+		return
+	}
+	files, err := commit.Files()
+	if err != nil {
+		gitError(w, err)
+		return
+	}
+	prefix := sl[1] + "-" + strings.ReplaceAll(ref, "/", "-") + "/"
+
+	switch ext {
+	case "tar.gz":
+		w.Header().Set("Content-Type", "application/x-gzip")
+		gw := gzip.NewWriter(w)
+		tw := tar.NewWriter(gw)
+		files.ForEach(func(file *object.File) error {
+			return writeArchiveFile(file, func(size int64) (io.Writer, error) {
+				return tw, tw.WriteHeader(&tar.Header{
+					Name:    prefix + file.Name,
+					Mode:    0644,
+					Size:    size,
+					ModTime: commit.Committer.When,
+				})
+			})
+		})
+		tw.Close()
+		gw.Close()
+	case "zip":
+		w.Header().Set("Content-Type", "application/zip")
+		zw := zip.NewWriter(w)
+		files.ForEach(func(file *object.File) error {
+			return writeArchiveFile(file, func(size int64) (io.Writer, error) {
+				return zw.Create(prefix + file.Name)
+			})
+		})
+		zw.Close()
+	default:
+		notFound(w)
+	}
+}
+
+func writeArchiveFile(file *object.File, create func(size int64) (io.Writer, error)) error {
+	r, err := file.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	dst, err := create(file.Size)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return err
+}
@@ -0,0 +1,46 @@
+package putinghtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// serveGistComments lists or creates the comments of gists/{id}/comments.
+func (f *fake) serveGistComments(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := f.gists[id]; !ok {
+		notFound(w)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		comments := f.gistComments[id]
+		if comments == nil {
+			comments = []*ghv3.GistComment{}
+		}
+		writeJSON(w, http.StatusOK, comments)
+	case http.MethodPost:
+		var req ghv3.GistComment
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if req.GetBody() == "" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "body is missing"})
+			return
+		}
+		now := ghv3.Timestamp{Time: time.Now()}
+		comment := &ghv3.GistComment{
+			ID:        ghv3.Int64(f.nextID()),
+			Body:      req.Body,
+			User:      &ghv3.User{Login: ghv3.String(Login)},
+			CreatedAt: &now,
+		}
+		f.gistComments[id] = append(f.gistComments[id], comment)
+		writeJSON(w, http.StatusCreated, comment)
+	default:
+		notFound(w)
+	}
+}
@@ -0,0 +1,80 @@
+package putinghtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const lfsPrefix = "/lfs-objects/"
+
+type lfsObject struct {
+	OID     string                     `json:"oid"`
+	Size    int64                      `json:"size"`
+	Actions map[string]lfsObjectAction `json:"actions,omitempty"`
+	Error   *lfsObjectError            `json:"error,omitempty"`
+}
+
+type lfsObjectAction struct {
+	Href string `json:"href"`
+}
+
+type lfsObjectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// serveLFSBatch answers the LFS batch API of a repository with basic transfers against serveLFSObject.
+func (f *fake) serveLFSBatch(w http.ResponseWriter, r *http.Request, key string) {
+	var req struct {
+		Operation string      `json:"operation"`
+		Objects   []lfsObject `json:"objects"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	objects := make([]lfsObject, 0, len(req.Objects))
+	for _, obj := range req.Objects {
+		href := f.url + lfsPrefix + key + "/" + obj.OID
+		_, ok := f.lfs[key+"/"+obj.OID]
+		switch {
+		case req.Operation == "upload":
+			obj.Actions = map[string]lfsObjectAction{"upload": {Href: href}}
+		case ok:
+			obj.Actions = map[string]lfsObjectAction{"download": {Href: href}}
+		default:
+			obj.Error = &lfsObjectError{Code: http.StatusNotFound, Message: "Object does not exist"}
+		}
+		objects = append(objects, obj)
+	}
+	w.Header().Set("Content-Type", "application/vnd.git-lfs+json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"transfer": "basic",
+		"objects":  objects,
+	})
+}
+
+// serveLFSObject stores and serves LFS objects at {owner}/{repo}/{oid}.
+func (f *fake) serveLFSObject(w http.ResponseWriter, r *http.Request, path string) {
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		f.lfs[path] = data
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		data, ok := f.lfs[path]
+		if !ok {
+			notFound(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+	default:
+		notFound(w)
+	}
+}
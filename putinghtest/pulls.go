@@ -0,0 +1,94 @@
+package putinghtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// servePulls creates pull requests with POST repos/{owner}/{repo}/pulls and
+// fast-forward merges them with PUT repos/{owner}/{repo}/pulls/{number}/merge.
+func (f *fake) servePulls(w http.ResponseWriter, r *http.Request, key string, sl []string) {
+	switch {
+	case len(sl) == 0 && r.Method == http.MethodPost:
+		var req ghv3.NewPullRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		if f.branchHash(key, req.GetHead()).IsZero() || f.branchHash(key, req.GetBase()).IsZero() {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+			return
+		}
+		number := len(f.pulls[key]) + 1
+		pr := &ghv3.PullRequest{
+			Number:  ghv3.Int(number),
+			State:   ghv3.String("open"),
+			Title:   req.Title,
+			Body:    req.Body,
+			HTMLURL: ghv3.String(fmt.Sprintf("%s/%s/pull/%d", f.url, key, number)),
+			Head:    &ghv3.PullRequestBranch{Ref: req.Head},
+			Base:    &ghv3.PullRequestBranch{Ref: req.Base},
+		}
+		f.pulls[key] = append(f.pulls[key], pr)
+		writeJSON(w, http.StatusCreated, pr)
+	case len(sl) == 2 && sl[1] == "merge" && r.Method == http.MethodPut:
+		number, err := strconv.Atoi(sl[0])
+		if err != nil || number < 1 || number > len(f.pulls[key]) {
+			notFound(w)
+			return
+		}
+		pr := f.pulls[key][number-1]
+		if pr.GetMerged() {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "Pull Request is not mergeable"})
+			return
+		}
+		head, base := f.branchHash(key, pr.GetHead().GetRef()), f.branchHash(key, pr.GetBase().GetRef())
+		st := f.repos[key]
+		headCommit, err := object.GetCommit(st, head)
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		baseCommit, err := object.GetCommit(st, base)
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		if ok, err := baseCommit.IsAncestor(headCommit); err != nil || !ok {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "Pull Request is not mergeable"})
+			return
+		}
+		err = st.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(pr.GetBase().GetRef()), head))
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		pr.Merged = ghv3.Bool(true)
+		pr.State = ghv3.String("closed")
+		writeJSON(w, http.StatusOK, &ghv3.PullRequestMergeResult{
+			SHA:    ghv3.String(head.String()),
+			Merged: ghv3.Bool(true),
+		})
+	default:
+		notFound(w)
+	}
+}
+
+// branchHash returns the head of branch in the repository key, zero when it does not exist.
+func (f *fake) branchHash(key, branch string) plumbing.Hash {
+	st, ok := f.repos[key]
+	if !ok {
+		return plumbing.ZeroHash
+	}
+	ref, err := st.Reference(plumbing.NewBranchReferenceName(branch))
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return ref.Hash()
+}
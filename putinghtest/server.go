@@ -0,0 +1,652 @@
+// Package putinghtest provides an in-memory fake of the GitHub endpoints used by putingh,
+// so code built on putingh can be tested without real credentials.
+package putinghtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+)
+
+const (
+	// Login is the user the fake server authenticates every token as.
+	Login = "putinghtest"
+	// DefaultBranch is the default branch reported for every repository.
+	DefaultBranch = "main"
+	// PrivateRepoPrefix starts the names of the repositories reported as private.
+	PrivateRepoPrefix = "private-"
+
+	apiPrefix    = "/api/v3/"
+	uploadPrefix = "/api/uploads/"
+	gistPrefix   = "/gist-raw/"
+)
+
+// NewServer starts a fake GitHub and returns it with a PutInGH pointed at it.
+// The server is closed when t finishes, and the returned PutInGH stages files
+// in t.TempDir() unless putingh.WithTmpDir is passed in options.
+func NewServer(t testing.TB, options ...putingh.Option) (*httptest.Server, *putingh.PutInGH) {
+	f := &fake{
+		gists:        map[string]*ghv3.Gist{},
+		gistCommits:  map[string][]*ghv3.GistCommit{},
+		gistComments: map[string][]*ghv3.GistComment{},
+		gistHistory:  map[string]*ghv3.Gist{},
+		pulls:        map[string][]*ghv3.PullRequest{},
+		releases:     map[string][]*ghv3.RepositoryRelease{},
+		assets:       map[int64][]byte{},
+		repos:        map[string]*memory.Storage{},
+		lfs:          map[string][]byte{},
+	}
+	srv := httptest.NewServer(f)
+	f.url = srv.URL
+
+	t.Cleanup(srv.Close)
+
+	opts := []putingh.Option{
+		putingh.WithHost(srv.URL),
+		putingh.WithAPIURL(srv.URL+apiPrefix, srv.URL+uploadPrefix),
+		putingh.WithTmpDir(t.TempDir()),
+	}
+	opts = append(opts, options...)
+	return srv, putingh.NewPutInGH("putinghtest-token", opts...)
+}
+
+type fake struct {
+	mu  sync.Mutex
+	url string
+	seq int64
+
+	gists        map[string]*ghv3.Gist
+	gistCommits  map[string][]*ghv3.GistCommit
+	gistComments map[string][]*ghv3.GistComment
+	gistHistory  map[string]*ghv3.Gist
+	pulls        map[string][]*ghv3.PullRequest
+	releases     map[string][]*ghv3.RepositoryRelease
+	assets       map[int64][]byte
+	repos        map[string]*memory.Storage
+	lfs          map[string][]byte
+}
+
+func (f *fake) nextID() int64 {
+	f.seq++
+	return f.seq
+}
+
+func (f *fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, apiPrefix):
+		f.serveAPI(w, r, strings.Split(strings.TrimPrefix(path, apiPrefix), "/"))
+	case strings.HasPrefix(path, uploadPrefix):
+		f.serveUpload(w, r, strings.Split(strings.TrimPrefix(path, uploadPrefix), "/"))
+	case strings.HasPrefix(path, codeloadPrefix):
+		f.serveArchive(w, r, strings.SplitN(strings.TrimPrefix(path, codeloadPrefix), "/", 4))
+	case strings.HasPrefix(path, lfsPrefix):
+		f.serveLFSObject(w, r, strings.TrimPrefix(path, lfsPrefix))
+	case strings.HasPrefix(path, gistPrefix):
+		f.serveGistRaw(w, r, strings.SplitN(strings.TrimPrefix(path, gistPrefix), "/", 4))
+	default:
+		sl := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+		if len(sl) != 3 {
+			notFound(w)
+			return
+		}
+		if strings.HasPrefix(sl[2], "releases/download/") {
+			f.serveDownload(w, r, sl[0], sl[1], strings.SplitN(strings.TrimPrefix(sl[2], "releases/download/"), "/", 2))
+			return
+		}
+		if sl[2] == "info/lfs/objects/batch" && r.Method == http.MethodPost {
+			f.serveLFSBatch(w, r, sl[0]+"/"+strings.TrimSuffix(sl[1], ".git"))
+			return
+		}
+		f.serveGit(w, r, sl[0]+"/"+strings.TrimSuffix(sl[1], ".git"), sl[2])
+	}
+}
+
+func (f *fake) serveAPI(w http.ResponseWriter, r *http.Request, sl []string) {
+	switch {
+	case len(sl) == 1 && sl[0] == "user":
+		writeJSON(w, http.StatusOK, &ghv3.User{Login: ghv3.String(Login)})
+	case len(sl) == 1 && sl[0] == "gists":
+		switch r.Method {
+		case http.MethodGet:
+			f.listGists(w, Login)
+		case http.MethodPost:
+			f.createGist(w, r)
+		default:
+			notFound(w)
+		}
+	case len(sl) == 2 && sl[0] == "users" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, &ghv3.User{Login: ghv3.String(sl[1])})
+	case len(sl) == 3 && sl[0] == "users" && sl[2] == "gists":
+		f.listGists(w, sl[1])
+	case len(sl) == 2 && sl[0] == "gists":
+		switch r.Method {
+		case http.MethodGet:
+			f.getGist(w, r, sl[1])
+		case http.MethodPatch:
+			f.editGist(w, r, sl[1])
+		default:
+			notFound(w)
+		}
+	case len(sl) == 3 && sl[0] == "gists" && sl[2] == "commits" && r.Method == http.MethodGet:
+		if _, ok := f.gists[sl[1]]; !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, f.gistCommits[sl[1]])
+	case len(sl) == 3 && sl[0] == "gists" && sl[2] == "comments":
+		f.serveGistComments(w, r, sl[1])
+	case len(sl) == 3 && sl[0] == "gists" && r.Method == http.MethodGet:
+		gist, ok := f.gistHistory[sl[1]+"/"+sl[2]]
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, gist)
+	case len(sl) == 3 && sl[0] == "repos" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, &ghv3.Repository{
+			Name:          ghv3.String(sl[2]),
+			Private:       ghv3.Bool(strings.HasPrefix(sl[2], PrivateRepoPrefix)),
+			FullName:      ghv3.String(sl[1] + "/" + sl[2]),
+			DefaultBranch: ghv3.String(DefaultBranch),
+		})
+	case len(sl) >= 4 && sl[0] == "repos" && (sl[3] == "tarball" || sl[3] == "zipball") && r.Method == http.MethodGet:
+		f.serveArchiveLink(w, r, sl[1]+"/"+sl[2], sl[3], strings.Join(sl[4:], "/"))
+	case len(sl) >= 4 && sl[0] == "repos" && sl[3] == "pulls":
+		f.servePulls(w, r, sl[1]+"/"+sl[2], sl[4:])
+	case len(sl) >= 4 && sl[0] == "repos" && sl[3] == "releases":
+		f.serveReleases(w, r, sl[1]+"/"+sl[2], sl[1], sl[2], sl[4:])
+	default:
+		notFound(w)
+	}
+}
+
+func (f *fake) gistView(gist *ghv3.Gist, content bool) *ghv3.Gist {
+	view := *gist
+	view.Files = map[ghv3.GistFilename]ghv3.GistFile{}
+	for name, file := range gist.Files {
+		if !content {
+			file.Content = nil
+		}
+		view.Files[name] = file
+	}
+	return &view
+}
+
+func (f *fake) listGists(w http.ResponseWriter, owner string) {
+	list := []*ghv3.Gist{}
+	for _, gist := range f.gists {
+		if gist.GetOwner().GetLogin() == owner {
+			list = append(list, f.gistView(gist, false))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GetUpdatedAt().After(list[j].GetUpdatedAt().Time)
+	})
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (f *fake) getGist(w http.ResponseWriter, r *http.Request, id string) {
+	gist, ok := f.gists[id]
+	if !ok {
+		notFound(w)
+		return
+	}
+	if commits := f.gistCommits[id]; len(commits) != 0 {
+		etag := `"` + commits[0].GetVersion() + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, f.gistView(gist, true))
+}
+
+type gistRequest struct {
+	Description *string                     `json:"description"`
+	Public      *bool                       `json:"public"`
+	Files       map[string]*json.RawMessage `json:"files"`
+}
+
+func (f *fake) createGist(w http.ResponseWriter, r *http.Request) {
+	var req gistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	now := ghv3.Timestamp{Time: time.Now()}
+	id := fmt.Sprintf("%032x", f.nextID())
+	gist := &ghv3.Gist{
+		ID:          ghv3.String(id),
+		Description: req.Description,
+		Public:      req.Public,
+		Owner:       &ghv3.User{Login: ghv3.String(Login)},
+		Files:       map[ghv3.GistFilename]ghv3.GistFile{},
+		HTMLURL:     ghv3.String(f.url + "/gist/" + Login + "/" + id),
+		CreatedAt:   &now,
+	}
+	if err := f.applyGistFiles(gist, req.Files); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": err.Error()})
+		return
+	}
+	f.gists[id] = gist
+	writeJSON(w, http.StatusCreated, f.gistView(gist, true))
+}
+
+func (f *fake) editGist(w http.ResponseWriter, r *http.Request, id string) {
+	gist, ok := f.gists[id]
+	if !ok {
+		notFound(w)
+		return
+	}
+	var req gistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	if req.Description != nil {
+		gist.Description = req.Description
+	}
+	if err := f.applyGistFiles(gist, req.Files); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, f.gistView(gist, true))
+}
+
+// applyGistFiles merges files into gist the way the GitHub API does,
+// a null file deletes it and files not mentioned are kept.
+func (f *fake) applyGistFiles(gist *ghv3.Gist, files map[string]*json.RawMessage) error {
+	for name, raw := range files {
+		if raw == nil || string(*raw) == "null" {
+			delete(gist.Files, ghv3.GistFilename(name))
+			continue
+		}
+		var file ghv3.GistFile
+		if err := json.Unmarshal(*raw, &file); err != nil {
+			return err
+		}
+		target := name
+		if file.Filename != nil && *file.Filename != "" {
+			target = *file.Filename
+		}
+		old, ok := gist.Files[ghv3.GistFilename(name)]
+		if file.Content == nil {
+			if !ok {
+				return fmt.Errorf("file %q has no content", name)
+			}
+			file.Content = old.Content
+		}
+		delete(gist.Files, ghv3.GistFilename(name))
+		content := *file.Content
+		gist.Files[ghv3.GistFilename(target)] = ghv3.GistFile{
+			Filename: ghv3.String(target),
+			Size:     ghv3.Int(len(content)),
+			Content:  ghv3.String(content),
+			Language: file.Language,
+			RawURL:   ghv3.String(f.url + gistPrefix + gist.GetOwner().GetLogin() + "/" + gist.GetID() + "/raw/" + target),
+		}
+	}
+	now := ghv3.Timestamp{Time: time.Now()}
+	gist.UpdatedAt = &now
+	version := fmt.Sprintf("%040x", f.nextID())
+	f.gistCommits[gist.GetID()] = append([]*ghv3.GistCommit{{
+		Version:     ghv3.String(version),
+		CommittedAt: &now,
+		User:        gist.Owner,
+	}}, f.gistCommits[gist.GetID()]...)
+	f.gistHistory[gist.GetID()+"/"+version] = f.gistView(gist, true)
+	return nil
+}
+
+func (f *fake) serveGistRaw(w http.ResponseWriter, r *http.Request, sl []string) {
+	if len(sl) != 4 || sl[2] != "raw" {
+		notFound(w)
+		return
+	}
+	gist, ok := f.gists[sl[1]]
+	if !ok {
+		notFound(w)
+		return
+	}
+	file, ok := gist.Files[ghv3.GistFilename(sl[3])]
+	if !ok {
+		notFound(w)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, file.GetContent())
+}
+
+func (f *fake) findRelease(key string, match func(*ghv3.RepositoryRelease) bool) *ghv3.RepositoryRelease {
+	for _, release := range f.releases[key] {
+		if match(release) {
+			return release
+		}
+	}
+	return nil
+}
+
+func (f *fake) serveReleases(w http.ResponseWriter, r *http.Request, key, owner, repo string, sl []string) {
+	switch {
+	case len(sl) == 0 && r.Method == http.MethodGet:
+		list := f.releases[key]
+		if list == nil {
+			list = []*ghv3.RepositoryRelease{}
+		}
+		writeJSON(w, http.StatusOK, list)
+	case len(sl) == 0 && r.Method == http.MethodPost:
+		var req ghv3.RepositoryRelease
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+			return
+		}
+		id := f.nextID()
+		now := ghv3.Timestamp{Time: time.Now()}
+		release := &ghv3.RepositoryRelease{
+			ID:          ghv3.Int64(id),
+			TagName:     req.TagName,
+			Name:        req.Name,
+			Body:        req.Body,
+			Draft:       ghv3.Bool(req.GetDraft()),
+			Prerelease:  ghv3.Bool(req.GetPrerelease()),
+			CreatedAt:   &now,
+			PublishedAt: &now,
+			HTMLURL:     ghv3.String(f.url + "/" + key + "/releases/tag/" + req.GetTagName()),
+			UploadURL:   ghv3.String(f.url + uploadPrefix + "repos/" + key + "/releases/" + strconv.FormatInt(id, 10) + "/assets{?name,label}"),
+			Assets:      []*ghv3.ReleaseAsset{},
+		}
+		f.releases[key] = append([]*ghv3.RepositoryRelease{release}, f.releases[key]...)
+		writeJSON(w, http.StatusCreated, release)
+	case len(sl) == 1 && sl[0] == "latest":
+		release := f.findRelease(key, func(release *ghv3.RepositoryRelease) bool {
+			return !release.GetDraft() && !release.GetPrerelease()
+		})
+		if release == nil {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, release)
+	case len(sl) == 2 && sl[0] == "tags":
+		release := f.findRelease(key, func(release *ghv3.RepositoryRelease) bool {
+			return release.GetTagName() == sl[1]
+		})
+		if release == nil {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, release)
+	case len(sl) == 2 && sl[0] == "assets" && r.Method == http.MethodDelete:
+		id, _ := strconv.ParseInt(sl[1], 10, 64)
+		for _, release := range f.releases[key] {
+			for i, asset := range release.Assets {
+				if asset.GetID() == id {
+					release.Assets = append(release.Assets[:i], release.Assets[i+1:]...)
+					delete(f.assets, id)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+		}
+		notFound(w)
+	case len(sl) >= 1:
+		id, _ := strconv.ParseInt(sl[0], 10, 64)
+		release := f.findRelease(key, func(release *ghv3.RepositoryRelease) bool {
+			return release.GetID() == id
+		})
+		if release == nil {
+			notFound(w)
+			return
+		}
+		switch {
+		case len(sl) == 1 && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, release)
+		case len(sl) == 1 && r.Method == http.MethodPatch:
+			var req ghv3.RepositoryRelease
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+				return
+			}
+			if req.Body != nil {
+				release.Body = req.Body
+			}
+			if req.Name != nil {
+				release.Name = req.Name
+			}
+			writeJSON(w, http.StatusOK, release)
+		case len(sl) == 2 && sl[1] == "assets" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, release.Assets)
+		default:
+			notFound(w)
+		}
+	default:
+		notFound(w)
+	}
+}
+
+func (f *fake) serveUpload(w http.ResponseWriter, r *http.Request, sl []string) {
+	if r.Method != http.MethodPost || len(sl) != 6 || sl[0] != "repos" || sl[3] != "releases" || sl[5] != "assets" {
+		notFound(w)
+		return
+	}
+	key := sl[1] + "/" + sl[2]
+	id, _ := strconv.ParseInt(sl[4], 10, 64)
+	release := f.findRelease(key, func(release *ghv3.RepositoryRelease) bool {
+		return release.GetID() == id
+	})
+	if release == nil {
+		notFound(w)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	for _, asset := range release.Assets {
+		if asset.GetName() == name {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "already_exists"})
+			return
+		}
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+	assetID := f.nextID()
+	now := ghv3.Timestamp{Time: time.Now()}
+	asset := &ghv3.ReleaseAsset{
+		ID:                 ghv3.Int64(assetID),
+		Name:               ghv3.String(name),
+		Size:               ghv3.Int(len(data)),
+		ContentType:        ghv3.String(r.Header.Get("Content-Type")),
+		State:              ghv3.String("uploaded"),
+		DownloadCount:      ghv3.Int(0),
+		BrowserDownloadURL: ghv3.String(f.url + "/" + key + "/releases/download/" + release.GetTagName() + "/" + name),
+		CreatedAt:          &now,
+		UpdatedAt:          &now,
+	}
+	release.Assets = append(release.Assets, asset)
+	f.assets[assetID] = data
+	writeJSON(w, http.StatusCreated, asset)
+}
+
+func (f *fake) serveDownload(w http.ResponseWriter, r *http.Request, owner, repo string, sl []string) {
+	if len(sl) != 2 {
+		notFound(w)
+		return
+	}
+	release := f.findRelease(owner+"/"+repo, func(release *ghv3.RepositoryRelease) bool {
+		return release.GetTagName() == sl[0]
+	})
+	if release == nil {
+		notFound(w)
+		return
+	}
+	for _, asset := range release.Assets {
+		if asset.GetName() == sl[1] {
+			asset.DownloadCount = ghv3.Int(asset.GetDownloadCount() + 1)
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(f.assets[asset.GetID()])
+			return
+		}
+	}
+	notFound(w)
+}
+
+type storerLoader struct {
+	storer storer.Storer
+}
+
+func (l storerLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	return l.storer, nil
+}
+
+// serveGit implements the smart HTTP git protocol over an in-memory repository,
+// repositories are created on first use.
+func (f *fake) serveGit(w http.ResponseWriter, r *http.Request, key, rest string) {
+	st, ok := f.repos[key]
+	if !ok {
+		st = memory.NewStorage()
+		f.repos[key] = st
+	}
+	srv := server.NewServer(storerLoader{storer: st})
+	ep, err := transport.NewEndpoint("/" + key)
+	if err != nil {
+		gitError(w, err)
+		return
+	}
+
+	switch {
+	case rest == "info/refs" && r.Method == http.MethodGet:
+		service := r.URL.Query().Get("service")
+		var ar *packp.AdvRefs
+		switch service {
+		case transport.UploadPackServiceName:
+			sess, err := srv.NewUploadPackSession(ep, nil)
+			if err != nil {
+				gitError(w, err)
+				return
+			}
+			ar, err = sess.AdvertisedReferencesContext(r.Context())
+			if err != nil {
+				gitError(w, err)
+				return
+			}
+			ar.Capabilities.Set(capability.Shallow)
+		case transport.ReceivePackServiceName:
+			sess, err := srv.NewReceivePackSession(ep, nil)
+			if err != nil {
+				gitError(w, err)
+				return
+			}
+			ar, err = sess.AdvertisedReferencesContext(r.Context())
+			if err != nil {
+				gitError(w, err)
+				return
+			}
+		default:
+			notFound(w)
+			return
+		}
+		ar.Prefix = [][]byte{[]byte("# service=" + service), pktline.Flush}
+		w.Header().Set("Content-Type", "application/x-"+service+"-advertisement")
+		ar.Encode(w)
+
+	case rest == transport.UploadPackServiceName && r.Method == http.MethodPost:
+		req := packp.NewUploadPackRequest()
+		err := req.UploadRequest.Decode(r.Body)
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		scanner := pktline.NewScanner(r.Body)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if bytes.Equal(line, []byte("done")) {
+				break
+			}
+			if hash, ok := bytes.CutPrefix(line, []byte("have ")); ok {
+				req.Haves = append(req.Haves, plumbing.NewHash(string(hash)))
+			}
+		}
+		var resp *packp.UploadPackResponse
+		if req.Depth.IsZero() {
+			sess, err := srv.NewUploadPackSession(ep, nil)
+			if err != nil {
+				gitError(w, err)
+				return
+			}
+			resp, err = sess.UploadPack(r.Context(), req)
+		} else {
+			resp, err = uploadShallow(r.Context(), st, req)
+		}
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		defer resp.Close()
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
+		resp.Encode(w)
+
+	case rest == transport.ReceivePackServiceName && r.Method == http.MethodPost:
+		req := packp.NewReferenceUpdateRequest()
+		err := req.Decode(r.Body)
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		sess, err := srv.NewReceivePackSession(ep, nil)
+		if err != nil {
+			gitError(w, err)
+			return
+		}
+		status, err := sess.ReceivePack(r.Context(), req)
+		if status == nil {
+			gitError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-git-receive-pack-result")
+		if req.Capabilities.Supports(capability.ReportStatus) {
+			status.Encode(w)
+		}
+
+	default:
+		notFound(w)
+	}
+}
+
+func gitError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
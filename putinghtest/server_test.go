@@ -0,0 +1,34 @@
+package putinghtest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestServerRoundTrip(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	for _, uri := range []string{
+		"git://putinghtest/repo/main/dir/name.txt",
+		"asset://putinghtest/repo/v1/name.txt",
+		"releasenotes://putinghtest/repo/v1",
+		"gist://putinghtest/*/name.txt",
+	} {
+		t.Run(uri, func(t *testing.T) {
+			_, err := putter.PutIn(ctx, uri, strings.NewReader("content of "+uri))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := putter.GetBytes(ctx, uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "content of " + uri; string(got) != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
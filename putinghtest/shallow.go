@@ -0,0 +1,119 @@
+package putinghtest
+
+import (
+	"context"
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/utils/ioutil"
+)
+
+// uploadShallow answers an upload-pack request with a depth, which the go-git server does not support,
+// by sending the commits within depth of the wants and marking the deepest ones with parents as shallow.
+func uploadShallow(ctx context.Context, st storer.Storer, req *packp.UploadPackRequest) (*packp.UploadPackResponse, error) {
+	depth, ok := req.Depth.(packp.DepthCommits)
+	if !ok || depth <= 0 {
+		depth = 1
+	}
+
+	clientShallows := map[plumbing.Hash]bool{}
+	for _, h := range req.Shallows {
+		clientShallows[h] = true
+	}
+
+	// everything the client has, its history stops at its own shallow commits
+	have := map[plumbing.Hash]bool{}
+	err := walkCommits(st, req.Haves, func(c *object.Commit, _ int) (bool, error) {
+		have[c.Hash] = true
+		objs, err := revlist.Objects(st, []plumbing.Hash{c.TreeHash}, nil)
+		if err != nil {
+			return false, err
+		}
+		for _, h := range objs {
+			have[h] = true
+		}
+		return !clientShallows[c.Hash], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ignore := make([]plumbing.Hash, 0, len(have))
+	for h := range have {
+		ignore = append(ignore, h)
+	}
+
+	var objs, shallows, unshallows []plumbing.Hash
+	err = walkCommits(st, req.Wants, func(c *object.Commit, level int) (bool, error) {
+		deeper := level+1 < int(depth)
+		if !deeper && c.NumParents() > 0 {
+			shallows = append(shallows, c.Hash)
+		} else if clientShallows[c.Hash] {
+			unshallows = append(unshallows, c.Hash)
+		}
+		if !have[c.Hash] {
+			objs = append(objs, c.Hash)
+			trees, err := revlist.Objects(st, []plumbing.Hash{c.TreeHash}, ignore)
+			if err != nil {
+				return false, err
+			}
+			for _, h := range trees {
+				if !have[h] {
+					have[h] = true
+					objs = append(objs, h)
+				}
+			}
+		}
+		return deeper, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := packfile.NewEncoder(pw, st, false).Encode(objs, 10)
+		pw.CloseWithError(err)
+	}()
+	resp := packp.NewUploadPackResponseWithPackfile(req, ioutil.NewContextReadCloser(ctx, pr))
+	resp.Shallows = shallows
+	resp.Unshallows = unshallows
+	return resp, nil
+}
+
+// walkCommits visits the commits reachable from hashes breadth first with their distance,
+// the parents of a commit are visited when fn returns true.
+func walkCommits(st storer.Storer, hashes []plumbing.Hash, fn func(c *object.Commit, level int) (bool, error)) error {
+	seen := map[plumbing.Hash]bool{}
+	level := 0
+	for len(hashes) > 0 {
+		var next []plumbing.Hash
+		for _, h := range hashes {
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			c, err := object.GetCommit(st, h)
+			if err == plumbing.ErrObjectNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			deeper, err := fn(c, level)
+			if err != nil {
+				return err
+			}
+			if deeper {
+				next = append(next, c.ParentHashes...)
+			}
+		}
+		hashes = next
+		level++
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package putingh
+
+// WithReadOnly makes every write fail with ErrReadOnly before any request is sent.
+func WithReadOnly(readOnly bool) Option {
+	return func(p *PutInGH) {
+		p.readOnly = readOnly
+	}
+}
+
+func (s *PutInGH) checkWritable() error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestReadOnly(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	pushGit(t, srv, "repo", "main", map[string]string{"name.txt": "git"})
+	reads := map[string]string{
+		"git://" + owner + "/repo/main/name.txt": "git",
+		"gist://" + owner + "/*/name.txt":        "gist",
+		"asset://" + owner + "/repo/v1/name.txt": "asset",
+	}
+	for uri, content := range reads {
+		if strings.HasPrefix(uri, "git://") {
+			continue
+		}
+		_, err := putter.PutIn(ctx, uri, strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	id := gistID(t, putter)
+
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "name.txt")
+	err := os.WriteFile(fname, []byte("file"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readOnly := newPutter(t, srv, putingh.WithReadOnly(true))
+	kv, err := readOnly.OpenGistKV(ctx, owner, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests atomic.Int64
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(rw, r)
+	})
+
+	content := func() io.Reader {
+		return strings.NewReader("changed")
+	}
+	writes := map[string]func() error{
+		"PutBytes": func() error {
+			_, err := readOnly.PutBytes(ctx, "asset://"+owner+"/repo/v1/name.txt", []byte("changed"))
+			return err
+		},
+		"PutInWithFile": func() error {
+			_, err := readOnly.PutInWithFile(ctx, "git://"+owner+"/repo/main/name.txt", fname)
+			return err
+		},
+		"PutInWithResult": func() error {
+			_, err := readOnly.PutInWithResult(ctx, "gist://"+owner+"/"+id+"/name.txt", content())
+			return err
+		},
+		"PutInBatch": func() error {
+			_, err := readOnly.PutInBatch(ctx, map[string]io.Reader{"git://" + owner + "/repo/main/name.txt": content()})
+			return err
+		},
+		"PutInGitCommit": func() error {
+			_, _, err := readOnly.PutInGitCommit(ctx, owner, "repo", "main", "name.txt", content())
+			return err
+		},
+		"PutInGitFiles": func() error {
+			_, err := readOnly.PutInGitFiles(ctx, owner, "repo", "main", map[string]io.Reader{"name.txt": content()})
+			return err
+		},
+		"PutInGitDir": func() error {
+			_, err := readOnly.PutInGitDir(ctx, owner, "repo", "main", "", dir)
+			return err
+		},
+		"PutInGitIfMatch": func() error {
+			_, err := readOnly.PutInGitIfMatch(ctx, owner, "repo", "main", "name.txt", "", content())
+			return err
+		},
+		"ReconcileGit": func() error {
+			_, err := readOnly.ReconcileGit(ctx, owner, "repo", "main", map[string]io.Reader{})
+			return err
+		},
+		"DeleteGlob": func() error {
+			_, err := readOnly.DeleteGlob(ctx, owner, "repo", "main", "*")
+			return err
+		},
+		"SwapGit": func() error {
+			_, err := readOnly.SwapGit(ctx, owner, "repo", "main", "name.txt", "other.txt")
+			return err
+		},
+		"GitBatch.Commit": func() error {
+			batch := readOnly.Batch(owner, "repo", "main")
+			batch.Add("name.txt", content())
+			_, err := batch.Commit(ctx)
+			return err
+		},
+		"PutInGistFiles": func() error {
+			_, err := readOnly.PutInGistFiles(ctx, owner, id, map[string]io.Reader{"name.txt": nil})
+			return err
+		},
+		"AddGistComment": func() error {
+			return readOnly.AddGistComment(ctx, id, "comment")
+		},
+		"GistKV.Set": func() error {
+			return kv.Set("key", []byte("value"))
+		},
+		"GistKV.Delete": func() error {
+			return kv.Delete("name.txt")
+		},
+		"PutInAssetFromURL": func() error {
+			_, err := readOnly.PutInAssetFromURL(ctx, owner, "repo", "v1", "copy.txt", srv.URL)
+			return err
+		},
+	}
+	for uri := range reads {
+		uri := uri
+		writes["PutIn "+uri] = func() error {
+			_, err := readOnly.PutIn(ctx, uri, content())
+			return err
+		}
+	}
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			requests.Store(0)
+			err := write()
+			if !errors.Is(err, putingh.ErrReadOnly) {
+				t.Fatalf("got %v, want ErrReadOnly", err)
+			}
+			if n := requests.Load(); n != 0 {
+				t.Fatalf("made %d requests before failing", n)
+			}
+		})
+	}
+
+	for uri, want := range reads {
+		got, err := readOnly.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("%s: got %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestReadOnlyCreateRelease(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithReadOnly(true), putingh.WithCreateReleaseIfMissing(true))
+	_, _, err := putter.GetOrCreateRelease(context.Background(), owner, "repo", "v1")
+	if !errors.Is(err, putingh.ErrReadOnly) {
+		t.Fatalf("got %v, want ErrReadOnly", err)
+	}
+}
+
+func TestReadOnlyEmptyBatch(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithReadOnly(true))
+	uris, err := putter.Batch(owner, "repo", "main").Commit(context.Background())
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	if uris != nil {
+		t.Fatalf("got %v, want nil", uris)
+	}
+}
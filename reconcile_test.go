@@ -0,0 +1,58 @@
+package putingh_test
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestReconcileGitWithPrefix(t *testing.T) {
+	for _, prefix := range []string{"docs", "docs/"} {
+		t.Run(prefix, func(t *testing.T) {
+			testReconcileGitWithPrefix(t, prefix)
+		})
+	}
+}
+
+func testReconcileGitWithPrefix(t *testing.T, prefix string) {
+	srv, putter := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"docs/old.md":  "old",
+		"docs/keep.md": "keep",
+		"docs2/a.md":   "sibling",
+		"docs.md":      "file",
+		"other.txt":    "other",
+	})
+
+	ctx := context.Background()
+	_, err := putter.ReconcileGitWithPrefix(ctx, owner, "repo", "main", prefix, map[string]io.Reader{
+		"docs/keep.md": strings.NewReader("keep"),
+		"docs/new.md":  strings.NewReader("new"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := headCommit(t, srv, "repo", "main").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		got = append(got, f.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{"docs.md", "docs/keep.md", "docs/new.md", "docs2/a.md", "other.txt"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
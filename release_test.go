@@ -0,0 +1,144 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestCreateReleaseIfMissing(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	uri := "asset://" + owner + "/repo/v1/name.txt"
+
+	strict := newPutter(t, srv, putingh.WithCreateReleaseIfMissing(false))
+	_, err := strict.PutIn(ctx, uri, strings.NewReader("content"))
+	if !errors.Is(err, putingh.ErrReleaseNotFound) {
+		t.Fatalf("got %v, want ErrReleaseNotFound", err)
+	}
+	_, err = putter.GetBytes(ctx, uri)
+	if !errors.Is(err, putingh.ErrReleaseNotFound) {
+		t.Fatalf("got %v, the release must not have been created", err)
+	}
+
+	_, err = putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = strict.PutIn(ctx, "asset://"+owner+"/repo/v1/other.txt", strings.NewReader("other"))
+	if err != nil {
+		t.Fatalf("a put to an existing release failed: %v", err)
+	}
+	got, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+}
+
+func TestReleaseTag(t *testing.T) {
+	for want, opts := range map[[2]string][]putingh.Option{
+		{"stable", "stable"}: nil,
+		{"stable", "v1.0.0-stable"}: {putingh.WithReleaseTag(func(release string) string {
+			return "v1.0.0-" + release
+		})},
+	} {
+		srv, _ := putinghtest.NewServer(t)
+		var created []*ghv3.RepositoryRelease
+		handler := srv.Config.Handler
+		srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/"+owner+"/repo/releases" {
+				body, _ := io.ReadAll(r.Body)
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				release := &ghv3.RepositoryRelease{}
+				json.Unmarshal(body, release)
+				created = append(created, release)
+			}
+			handler.ServeHTTP(rw, r)
+		})
+
+		putter := newPutter(t, srv, opts...)
+		ctx := context.Background()
+		uri := "asset://" + owner + "/repo/stable/name.txt"
+		_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(created) != 1 {
+			t.Fatalf("created %d releases, want 1", len(created))
+		}
+		if got := [2]string{created[0].GetName(), created[0].GetTagName()}; got != want {
+			t.Fatalf("created release %q tagged %q, want %q tagged %q", got[0], got[1], want[0], want[1])
+		}
+		// the asset is found again under the release name
+		got, err := putter.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "content" {
+			t.Fatalf("got %q, want %q", got, "content")
+		}
+	}
+}
+
+func TestGetOrCreateRelease(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+
+	strict := newPutter(t, srv, putingh.WithCreateReleaseIfMissing(false))
+	_, _, err := strict.GetOrCreateRelease(ctx, owner, "repo", "v1")
+	if !errors.Is(err, putingh.ErrReleaseNotFound) {
+		t.Fatalf("got %v, want ErrReleaseNotFound", err)
+	}
+
+	id, uploadURL, err := putter.GetOrCreateRelease(ctx, owner, "repo", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == 0 {
+		t.Fatal("got no release ID")
+	}
+	if strings.Contains(uploadURL, "{") {
+		t.Fatalf("upload URL %q still has its template", uploadURL)
+	}
+	resolvedID, resolvedURL, err := strict.GetOrCreateRelease(ctx, owner, "repo", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolvedID != id || resolvedURL != uploadURL {
+		t.Fatalf("resolved %d %q, want the created %d %q", resolvedID, resolvedURL, id, uploadURL)
+	}
+
+	// an external uploader only needs the upload URL
+	req, err := http.NewRequest(http.MethodPost, uploadURL+"?name=name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("upload got %s", resp.Status)
+	}
+	got, err := putter.GetBytes(ctx, "asset://"+owner+"/repo/v1/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+}
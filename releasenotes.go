@@ -0,0 +1,54 @@
+package putingh
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// GetFromReleaseNotes returns the body of release.
+func (s *PutInGH) GetFromReleaseNotes(ctx context.Context, owner, repo, release string) (io.Reader, error) {
+	if err := s.checkAllowed("releasenotes", owner, repo); err != nil {
+		return nil, err
+	}
+	repositoryRelease, err := s.getRelease(ctx, owner, repo, release)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(repositoryRelease.GetBody()), nil
+}
+
+func (s *PutInGH) putInReleaseNotesWithFile(ctx context.Context, owner, repo, release string, filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return s.putInReleaseNotes(ctx, owner, repo, release, f)
+}
+
+// putInReleaseNotes replaces the body of release with r and returns the release page URL.
+func (s *PutInGH) putInReleaseNotes(ctx context.Context, owner, repo, release string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(s.limitReader(r))
+	if err != nil {
+		return "", err
+	}
+	repositoryRelease, err := s.getRelease(ctx, owner, repo, release)
+	if err != nil {
+		return "", err
+	}
+	err = s.checkRepoVisibility(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	repositoryRelease, _, err = s.cliv3.Repositories.EditRelease(ctx, owner, repo, repositoryRelease.GetID(), &ghv3.RepositoryRelease{
+		Body: ghv3.String(string(data)),
+	})
+	if err != nil {
+		return "", err
+	}
+	return repositoryRelease.GetHTMLURL(), nil
+}
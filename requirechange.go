@@ -0,0 +1,76 @@
+package putingh
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithRequireChange makes git, gist and asset puts fail with ErrNoChange when the content is already there,
+// instead of succeeding without writing anything.
+func WithRequireChange(require bool) Option {
+	return func(p *PutInGH) {
+		p.requireChange = require
+	}
+}
+
+// assetUnchanged reports whether asset already holds the content opened by open,
+// only an asset of the same size is downloaded to be compared.
+func (s *PutInGH) assetUnchanged(ctx context.Context, asset *ghv3.ReleaseAsset, open func() (io.Reader, int64, error)) (bool, error) {
+	if open == nil || asset.BrowserDownloadURL == nil {
+		return false, nil
+	}
+	r, size, err := open()
+	if err != nil {
+		return false, err
+	}
+	defer closeReader(r)
+	if int64(asset.GetSize()) != size {
+		return false, nil
+	}
+	resp, err := s.httpGet(ctx, *asset.BrowserDownloadURL)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return readersEqual(resp.Body, r)
+}
+
+// gistFileUnchanged reports whether the file name of gist already holds content.
+func (s *PutInGH) gistFileUnchanged(ctx context.Context, gist *ghv3.Gist, name, content string) (bool, error) {
+	file, ok := gist.Files[ghv3.GistFilename(name)]
+	if !ok || file.GetSize() != len(content) {
+		return false, nil
+	}
+	r, err := s.readGistFile(ctx, file)
+	if err != nil {
+		return false, err
+	}
+	defer closeReader(r)
+	return readersEqual(r, bytes.NewReader([]byte(content)))
+}
+
+func readersEqual(a, b io.Reader) (bool, error) {
+	bufA := make([]byte, 32<<10)
+	bufB := make([]byte, 32<<10)
+	for {
+		n, errA := io.ReadFull(a, bufA)
+		if errA != nil && errA != io.EOF && errA != io.ErrUnexpectedEOF {
+			return false, errA
+		}
+		m, errB := io.ReadFull(b, bufB[:n])
+		if errB != nil && errB != io.EOF && errB != io.ErrUnexpectedEOF {
+			return false, errB
+		}
+		if m != n || !bytes.Equal(bufA[:n], bufB[:m]) {
+			return false, nil
+		}
+		if errA != nil {
+			// a is done, b must be too
+			k, _ := io.ReadFull(b, bufB[:1])
+			return k == 0, nil
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package putingh_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// putTwice puts content to uri twice with putter and expects ErrNoChange from the second put.
+func putTwice(t *testing.T, putter *putingh.PutInGH, uri, content string) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, uri, strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = putter.PutIn(ctx, uri, strings.NewReader(content))
+	if !errors.Is(err, putingh.ErrNoChange) {
+		t.Fatalf("got %v, want ErrNoChange", err)
+	}
+}
+
+func TestRequireChangeGit(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithRequireChange(true))
+	putTwice(t, putter, "git://"+owner+"/repo/main/name.txt", "content")
+}
+
+func TestRequireChangeGitBare(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	dir := t.TempDir()
+	_, err := gogit.PlainInit(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putter := newPutter(t, srv, putingh.WithBareRepoPath(dir), putingh.WithRequireChange(true))
+	putTwice(t, putter, "git://"+owner+"/repo/main/name.txt", "content")
+}
+
+func TestRequireChangeGitContents(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	content := "content"
+	sha := plumbing.ComputeHash(plumbing.BlobObject, []byte(content)).String()
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/repos/"+owner+"/repo/contents/name.txt") {
+			http.NotFound(rw, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			http.Error(rw, "unexpected", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(rw).Encode(map[string]string{
+			"type": "file",
+			"name": "name.txt",
+			"path": "name.txt",
+			"sha":  sha,
+		})
+	})
+	putter := newPutter(t, srv, putingh.WithGitContentsAPI(true), putingh.WithRequireChange(true))
+	_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", strings.NewReader(content))
+	if !errors.Is(err, putingh.ErrNoChange) {
+		t.Fatalf("got %v, want ErrNoChange", err)
+	}
+}
+
+func TestRequireChangeGist(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithRequireChange(true))
+	putTwice(t, putter, "gist://"+owner+"/*/name.txt", "content")
+}
+
+func TestRequireChangeGistSplit(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithGistAutoSplit(true), putingh.WithRequireChange(true))
+	putTwice(t, putter, "gist://"+owner+"/*/large.txt", strings.Repeat("0123456789abcdef", 1<<16+1))
+	putTwice(t, putter, "gist://"+owner+"/*/small.txt", "content")
+}
+
+func TestRequireChangeAsset(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithRequireChange(true))
+	putTwice(t, putter, "asset://"+owner+"/repo/v1/name.txt", "content")
+}
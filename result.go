@@ -0,0 +1,115 @@
+package putingh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// PutResult describes content written by PutInWithResult.
+type PutResult struct {
+	URL string
+	// SHA256 is the hex SHA-256 of the content itself, for git it is not the object hash
+	// git computes over the "blob <size>" header and the content.
+	SHA256 string
+	Size   int64
+}
+
+// PutInWithResult is PutIn that also returns the SHA-256 and size of the content,
+// computed while it is read from r. The digest is of the content as stored, after the line endings
+// set with WithLineEnding and the gist binary encoding are applied.
+func (s *PutInGH) PutInWithResult(ctx context.Context, uri string, r io.Reader) (*PutResult, error) {
+	d := &putDigest{
+		hash: sha256.New(),
+	}
+	ctx = context.WithValue(ctx, putDigestContextKey{}, d)
+	u, err := s.PutIn(ctx, uri, r)
+	if err != nil {
+		return nil, err
+	}
+	// drain what a put did not need to read, such as an unchanged file
+	if d.r != nil {
+		_, err = io.Copy(io.Discard, d.r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &PutResult{
+		URL:    u,
+		SHA256: hex.EncodeToString(d.hash.Sum(nil)),
+		Size:   d.size,
+	}, nil
+}
+
+type hashReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+func (h *hashReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	h.hash.Write(p[:n])
+	h.size += int64(n)
+	return n, err
+}
+
+type putDigestContextKey struct{}
+
+// putDigest collects the digest of PutInWithResult.
+type putDigest struct {
+	hash hash.Hash
+	size int64
+	// stored is set once the stored content was recorded as a whole
+	stored bool
+	r      io.Reader
+}
+
+func (d *putDigest) reset(data []byte) {
+	d.hash.Reset()
+	d.hash.Write(data)
+	d.size = int64(len(data))
+}
+
+// digestReader hashes the content read through it into the putDigest of ctx, if any.
+// Rewinding a seekable r to where it started restarts the digest.
+func digestReader(ctx context.Context, r io.Reader) io.Reader {
+	d, _ := ctx.Value(putDigestContextKey{}).(*putDigest)
+	if d == nil || d.r != nil {
+		// a put nested in a put, such as one by gist URL, is already hashed
+		return r
+	}
+	d.r = keepReaderLen(&putDigestReader{r: r, d: d}, r, func() {
+		if !d.stored {
+			d.reset(nil)
+		}
+	})
+	return d.r
+}
+
+// recordStoredContent makes data the digest of the putDigest of ctx,
+// for content that is encoded before it is stored.
+func recordStoredContent(ctx context.Context, data []byte) {
+	d, _ := ctx.Value(putDigestContextKey{}).(*putDigest)
+	if d == nil {
+		return
+	}
+	d.reset(data)
+	d.stored = true
+}
+
+type putDigestReader struct {
+	r io.Reader
+	d *putDigest
+}
+
+func (h *putDigestReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	if !h.d.stored {
+		h.d.hash.Write(p[:n])
+		h.d.size += int64(n)
+	}
+	return n, err
+}
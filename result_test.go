@@ -0,0 +1,107 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func checkResult(t *testing.T, res *putingh.PutResult, stored []byte) {
+	t.Helper()
+	if want := sha256Hex(stored); res.SHA256 != want {
+		t.Errorf("got SHA256 %s, want %s", res.SHA256, want)
+	}
+	if res.Size != int64(len(stored)) {
+		t.Errorf("got size %d, want %d", res.Size, len(stored))
+	}
+}
+
+func TestPutInWithResult(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	for _, uri := range []string{
+		"git://" + owner + "/repo/main/name.txt",
+		"gist://" + owner + "/*/name.txt",
+		"asset://" + owner + "/repo/v1/name.txt",
+	} {
+		res, err := putter.PutInWithResult(ctx, uri, strings.NewReader("content"))
+		if err != nil {
+			t.Fatalf("%s: %v", uri, err)
+		}
+		checkResult(t, res, []byte("content"))
+
+		// an unchanged put hashes the content too
+		res, err = putter.PutInWithResult(ctx, uri, strings.NewReader("content"))
+		if err != nil {
+			t.Fatalf("%s: %v", uri, err)
+		}
+		checkResult(t, res, []byte("content"))
+	}
+}
+
+func TestPutInWithResultLineEnding(t *testing.T) {
+	_, putter := putinghtest.NewServer(t, putingh.WithLineEnding(func(name string) putingh.LineEnding {
+		return putingh.LineEndingLF
+	}))
+	ctx := context.Background()
+	uri := "git://" + owner + "/repo/main/name.txt"
+	res, err := putter.PutInWithResult(ctx, uri, strings.NewReader("a\r\nb\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stored) != "a\nb\n" {
+		t.Fatalf("got %q stored, want LF line endings", stored)
+	}
+	checkResult(t, res, stored)
+}
+
+func TestPutInWithResultGistBinary(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t, putingh.WithGistBinaryEncoding(true))
+	ctx := context.Background()
+	data := []byte{0, 1, 2, 0xff}
+	res, err := putter.PutInWithResult(ctx, "gist://"+owner+"/*/bin", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gistID(t, putter)
+	cli, err := ghv3.NewClient(nil).WithEnterpriseURLs(srv.URL+"/api/v3/", srv.URL+"/api/uploads/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gist, _, err := cli.Gists.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := gist.Files["bin"]
+	checkResult(t, res, []byte(file.GetContent()))
+}
+
+func TestPutInWithResultAssetStreams(t *testing.T) {
+	var out bytes.Buffer
+	_, putter := putinghtest.NewServer(t, putingh.WithOutput(&out))
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	res, err := putter.PutInWithResult(context.Background(), "asset://"+owner+"/repo/v1/name.bin", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, res, data)
+	if !strings.Contains(out.String(), "from the reader") {
+		t.Fatalf("asset was not uploaded straight from the reader: %q", out.String())
+	}
+}
@@ -0,0 +1,288 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	gogithttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithRetry retries failed GitHub API, download and git transport requests up to maxRetries times,
+// waiting with full jitter exponential backoff between baseDelay and maxDelay.
+// A wait the server asks for with Retry-After or a rate limit reset is not bounded by maxDelay.
+func WithRetry(maxRetries int, baseDelay, maxDelay time.Duration) Option {
+	return func(p *PutInGH) {
+		p.retry = &retrier{
+			maxRetries: maxRetries,
+			baseDelay:  baseDelay,
+			maxDelay:   maxDelay,
+			rand:       p.randSource(),
+		}
+	}
+}
+
+// WithRandSource sets the random source used for the retry jitter.
+func WithRandSource(src rand.Source) Option {
+	return func(p *PutInGH) {
+		p.randSrc = src
+		if p.retry != nil {
+			p.retry.rand = p.randSource()
+		}
+	}
+}
+
+func (s *PutInGH) randSource() *lockedRand {
+	src := s.randSrc
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+	return &lockedRand{rand: rand.New(src)}
+}
+
+type lockedRand struct {
+	mut  sync.Mutex
+	rand *rand.Rand
+}
+
+func (l *lockedRand) Int63n(n int64) int64 {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.rand.Int63n(n)
+}
+
+type retrier struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	rand       *lockedRand
+}
+
+// backoff returns a random delay in [0, min(maxDelay, baseDelay*2^attempt)].
+func (r *retrier) backoff(attempt int) time.Duration {
+	d := r.baseDelay
+	for i := 0; i < attempt && (r.maxDelay <= 0 || d < r.maxDelay); i++ {
+		d *= 2
+	}
+	if r.maxDelay > 0 && d > r.maxDelay {
+		d = r.maxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(r.rand.Int63n(int64(d) + 1))
+}
+
+func (r *retrier) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// do calls fn until it succeeds, fails with an error that is not retryable, or the retries run out.
+func (r *retrier) do(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || r == nil || attempt >= r.maxRetries || !retryable(err) {
+			return err
+		}
+		if werr := r.wait(ctx, r.backoff(attempt)); werr != nil {
+			return err
+		}
+	}
+}
+
+// WithRetryClassifier replaces DefaultRetryClassifier in deciding whether a failed request is retried,
+// resp is nil when the request failed with err before a response.
+func WithRetryClassifier(fn func(resp *http.Response, err error) bool) Option {
+	return func(p *PutInGH) {
+		p.retryClassifier = fn
+	}
+}
+
+// DefaultRetryClassifier retries network errors, 5xx, 429 and rate limited responses.
+func DefaultRetryClassifier(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if resp == nil {
+			var netErr net.Error
+			return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+		}
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		retry, _ := forbiddenRetryDelay(resp)
+		return retry
+	}
+	return isRetryableStatus(resp.StatusCode)
+}
+
+func (s *PutInGH) classifyRetry(resp *http.Response, err error) bool {
+	if s.retryClassifier != nil {
+		return s.retryClassifier(resp, err)
+	}
+	return DefaultRetryClassifier(resp, err)
+}
+
+// retryGit runs a git transport operation with the configured retries.
+func (s *PutInGH) retryGit(ctx context.Context, fn func() error) error {
+	return s.retry.do(ctx, s.isRetryableGitError, fn)
+}
+
+func (s *PutInGH) isRetryableGitError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// go-git hides the status of a response in an UnexpectedError, which does not unwrap
+	var unexpected *plumbing.UnexpectedError
+	if errors.As(err, &unexpected) {
+		err = unexpected.Err
+	}
+	var resp *http.Response
+	var httpErr *gogithttp.Err
+	if errors.As(err, &httpErr) {
+		resp = httpErr.Response
+	}
+	return s.classifyRetry(resp, err)
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryTransport retries requests failing with network errors, 5xx or rate limit responses.
+type retryTransport struct {
+	retry    *retrier
+	classify func(resp *http.Response, err error) bool
+	base     http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.retry.maxRetries {
+			return resp, err
+		}
+		retry, delay := t.retryable(req, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if delay > 0 {
+			delay += t.retry.backoff(0)
+		} else {
+			delay = t.retry.backoff(attempt)
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if werr := t.retry.wait(req.Context(), delay); werr != nil {
+			return nil, werr
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether the request should be sent again, and the wait the server asked for if any.
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) (bool, time.Duration) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false, 0
+	}
+	if err != nil && req.Context().Err() != nil {
+		return false, 0
+	}
+	if !t.classify(resp, err) {
+		return false, 0
+	}
+	if resp == nil {
+		return true, 0
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		_, d := forbiddenRetryDelay(resp)
+		return true, d
+	}
+	d, _ := rateLimitDelay(resp)
+	return true, d
+}
+
+// forbiddenRetryDelay reports whether a 403 response is a rate limit, and the wait until it is lifted if known.
+func forbiddenRetryDelay(resp *http.Response) (bool, time.Duration) {
+	// CheckResponse keeps the body readable for the caller.
+	body := resp.Body
+	rerr := ghv3.CheckResponse(resp)
+	body.Close()
+	var abuseErr *ghv3.AbuseRateLimitError
+	if errors.As(rerr, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return true, *abuseErr.RetryAfter
+		}
+		return true, 0
+	}
+	var rateErr *ghv3.RateLimitError
+	if errors.As(rerr, &rateErr) {
+		return true, time.Until(rateErr.Rate.Reset.Time)
+	}
+	return false, 0
+}
+
+// rateLimitDelay returns the wait the server asked for with Retry-After or X-RateLimit-Reset.
+func rateLimitDelay(resp *http.Response) (time.Duration, bool) {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil {
+			return time.Duration(sec) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if v := resp.Header.Get("X-RateLimit-Reset"); v != "" {
+			if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return time.Until(time.Unix(sec, 0)), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func (s *PutInGH) withRetryTransport(cli *http.Client) *http.Client {
+	if s.retry == nil || s.retry.maxRetries <= 0 {
+		return cli
+	}
+	base := cli.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := *cli
+	c.Transport = &retryTransport{
+		retry:    s.retry,
+		classify: s.classifyRetry,
+		base:     base,
+	}
+	return &c
+}
@@ -0,0 +1,104 @@
+package putingh_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestRetryHonorsRetryAfterBeyondMaxDelay(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "asset://"+owner+"/repo/v1/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var limited atomic.Bool
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v3/") && limited.CompareAndSwap(false, true) {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusForbidden)
+			rw.Write([]byte(`{"message":"You have exceeded a secondary rate limit.","documentation_url":"https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits"}`))
+			return
+		}
+		handler.ServeHTTP(rw, r)
+	})
+
+	retrying := newPutter(t, srv, putingh.WithRetry(1, time.Millisecond, 10*time.Millisecond))
+	start := time.Now()
+	got, err := retrying.GetBytes(ctx, "asset://"+owner+"/repo/v1/name.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+	if !limited.Load() {
+		t.Fatal("the rate limit was not hit")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+}
+
+// zeroSource always draws 0, so full jitter waits nothing however long the backoff is.
+type zeroSource struct {
+	draws atomic.Int64
+}
+
+func (s *zeroSource) Int63() int64 {
+	s.draws.Add(1)
+	return 0
+}
+
+func (s *zeroSource) Seed(int64) {}
+
+func TestRetryJitterFromRandSource(t *testing.T) {
+	for name, c := range map[string]struct {
+		uri    string
+		prefix string
+	}{
+		"api": {"gist://" + owner + "/*/name.txt", "/api/v3/"},
+		"git": {"git://" + owner + "/repo/main/name.txt", "/" + owner + "/repo/"},
+	} {
+		uri, prefix := c.uri, c.prefix
+		t.Run(name, func(t *testing.T) {
+			srv, _ := putinghtest.NewServer(t)
+			var failures atomic.Int64
+			handler := srv.Config.Handler
+			srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, prefix) && failures.Add(1) <= 2 {
+					http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				handler.ServeHTTP(rw, r)
+			})
+
+			src := &zeroSource{}
+			// a real wait of the hour long backoff would time the test out
+			putter := newPutter(t, srv,
+				putingh.WithRetry(3, time.Hour, time.Hour),
+				putingh.WithRandSource(src),
+			)
+			_, err := putter.PutIn(context.Background(), uri, strings.NewReader("content"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if failures.Load() <= 2 {
+				t.Fatal("the failures were not hit")
+			}
+			if src.draws.Load() == 0 {
+				t.Fatal("the jitter was not drawn from the rand source")
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SchemeHandler reads and writes the contents behind the URIs of one scheme.
+type SchemeHandler interface {
+	Get(ctx context.Context, target *url.URL) (io.Reader, error)
+	Put(ctx context.Context, target *url.URL, r io.Reader) (string, error)
+}
+
+// schemeFilePutter is implemented by handlers that write a local file better than its reader.
+type schemeFilePutter interface {
+	PutFile(ctx context.Context, target *url.URL, filename string) (string, error)
+}
+
+// RegisterScheme makes GetFrom and PutIn use handler for the URIs of scheme,
+// it replaces the handler registered before, including the built-in ones.
+func (s *PutInGH) RegisterScheme(scheme string, handler SchemeHandler) {
+	s.schemes.Store(scheme, handler)
+}
+
+func (s *PutInGH) schemeHandler(target *url.URL) (SchemeHandler, error) {
+	handler, ok := s.schemes.Load(target.Scheme)
+	if !ok {
+		return nil, fmt.Errorf("%q not support", target.String())
+	}
+	return handler.(SchemeHandler), nil
+}
+
+func (s *PutInGH) registerBuiltinSchemes() {
+	s.RegisterScheme("git", gitScheme{s})
+	s.RegisterScheme("asset", assetScheme{s})
+	s.RegisterScheme("gist", gistScheme{s})
+	s.RegisterScheme("releasenotes", releaseNotesScheme{s})
+	s.RegisterScheme("archive", archiveScheme{s})
+	s.RegisterScheme("http", gistURLScheme{s})
+	s.RegisterScheme("https", gistURLScheme{s})
+}
+
+type gitScheme struct {
+	s *PutInGH
+}
+
+func (h gitScheme) split(target *url.URL) (repo, branch, name string, err error) {
+	repo, branch, name, ok := splitGitPath(target.Path)
+	if !ok {
+		return "", "", "", fmt.Errorf("%q not match git://owner/repository/branch/name", target.String())
+	}
+	return repo, branch, name, nil
+}
+
+func (h gitScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	repo, branch, name, err := h.split(target)
+	if err != nil {
+		return nil, err
+	}
+	return h.s.GetFromGit(ctx, target.Host, repo, branch, name)
+}
+
+func (h gitScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	repo, branch, name, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInGit(ctx, target.Host, repo, branch, name, r)
+}
+
+func (h gitScheme) PutFile(ctx context.Context, target *url.URL, filename string) (string, error) {
+	repo, branch, name, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInGitWithFile(ctx, target.Host, repo, branch, name, filename)
+}
+
+type assetScheme struct {
+	s *PutInGH
+}
+
+func (h assetScheme) split(target *url.URL) ([]string, error) {
+	sl := strings.SplitN(target.Path, "/", 4)
+	if len(sl) != 4 {
+		return nil, fmt.Errorf("%q not match asset://owner/repository/release/name", target.String())
+	}
+	return sl, nil
+}
+
+func (h assetScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return nil, err
+	}
+	return h.s.GetFromReleasesAsset(ctx, target.Host, sl[1], sl[2], sl[3])
+}
+
+func (h assetScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInReleasesAsset(ctx, target.Host, sl[1], sl[2], sl[3], r)
+}
+
+func (h assetScheme) PutFile(ctx context.Context, target *url.URL, filename string) (string, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInReleasesAssetWithFile(ctx, target.Host, sl[1], sl[2], sl[3], filename)
+}
+
+type gistScheme struct {
+	s *PutInGH
+}
+
+func (h gistScheme) split(target *url.URL) ([]string, error) {
+	sl := strings.SplitN(target.Path, "/", 3)
+	if len(sl) != 3 {
+		return nil, fmt.Errorf("%q not match gist://owner/gist_id/name", target.String())
+	}
+	return sl, nil
+}
+
+func (h gistScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return nil, err
+	}
+	return h.s.GetFromGist(ctx, target.Host, sl[1], sl[2])
+}
+
+func (h gistScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInGist(ctx, target.Host, sl[1], sl[2], r)
+}
+
+func (h gistScheme) PutFile(ctx context.Context, target *url.URL, filename string) (string, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInGistWithFile(ctx, target.Host, sl[1], sl[2], filename)
+}
+
+type releaseNotesScheme struct {
+	s *PutInGH
+}
+
+func (h releaseNotesScheme) split(target *url.URL) ([]string, error) {
+	sl := strings.SplitN(target.Path, "/", 3)
+	if len(sl) != 3 {
+		return nil, fmt.Errorf("%q not match releasenotes://owner/repository/release", target.String())
+	}
+	return sl, nil
+}
+
+func (h releaseNotesScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return nil, err
+	}
+	return h.s.GetFromReleaseNotes(ctx, target.Host, sl[1], sl[2])
+}
+
+func (h releaseNotesScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInReleaseNotes(ctx, target.Host, sl[1], sl[2], r)
+}
+
+func (h releaseNotesScheme) PutFile(ctx context.Context, target *url.URL, filename string) (string, error) {
+	sl, err := h.split(target)
+	if err != nil {
+		return "", err
+	}
+	return h.s.putInReleaseNotesWithFile(ctx, target.Host, sl[1], sl[2], filename)
+}
+
+type archiveScheme struct {
+	s *PutInGH
+}
+
+func (h archiveScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	sl := strings.SplitN(target.Path, "/", 3)
+	if len(sl) < 2 || sl[1] == "" {
+		return nil, fmt.Errorf("%q not match archive://owner/repository/ref?format=tarball", target.String())
+	}
+	ref := ""
+	if len(sl) == 3 {
+		ref = sl[2]
+	}
+	return h.s.GetFromArchive(ctx, target.Host, sl[1], ref, target.Query().Get("format"))
+}
+
+func (h archiveScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	return "", fmt.Errorf("%q not support", target.String())
+}
+
+// gistURLScheme handles gist page and raw URLs as the gist they point to.
+type gistURLScheme struct {
+	s *PutInGH
+}
+
+// lineEnding is the mode of the gist file, when the URL path, which GetFrom and PutIn convert by, does not end with its name.
+func (h gistURLScheme) lineEnding(target *url.URL, file string) LineEnding {
+	if h.s.lineEndingOf(target.Path) != LineEndingAsIs {
+		return LineEndingAsIs
+	}
+	return h.s.lineEndingOf(file)
+}
+
+func (h gistURLScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	owner, id, file, err := h.s.resolveGist(ctx, target.String())
+	if err != nil {
+		return nil, err
+	}
+	r, err := h.s.GetFromGist(ctx, owner, id, file)
+	if err != nil {
+		return nil, err
+	}
+	if mode := h.lineEnding(target, file); h.s.lineEndingOnRead && mode != LineEndingAsIs {
+		return &readCloser{
+			Reader:  normalizeLineEnding(r, mode),
+			closers: []io.Closer{closerFunc(func() error { return closeReader(r) })},
+		}, nil
+	}
+	return r, nil
+}
+
+func (h gistURLScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	owner, id, file, err := h.s.resolveGist(ctx, target.String())
+	if err != nil {
+		return "", err
+	}
+	if err := h.s.checkAllowed("gist", owner, id); err != nil {
+		return "", err
+	}
+	if mode := h.lineEnding(target, file); mode != LineEndingAsIs {
+		r = normalizeLineEnding(r, mode)
+	}
+	return h.s.putInGist(ctx, owner, id, file, r)
+}
+
+func (h gistURLScheme) PutFile(ctx context.Context, target *url.URL, filename string) (string, error) {
+	owner, id, file, err := h.s.resolveGist(ctx, target.String())
+	if err != nil {
+		return "", err
+	}
+	if err := h.s.checkAllowed("gist", owner, id); err != nil {
+		return "", err
+	}
+	if mode := h.lineEnding(target, file); mode != LineEndingAsIs {
+		f, err := os.Open(filename)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		return h.s.putInGist(ctx, owner, id, file, normalizeLineEnding(f, mode))
+	}
+	return h.s.putInGistWithFile(ctx, owner, id, file, filename)
+}
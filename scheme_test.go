@@ -0,0 +1,111 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+var errMemNotFound = errors.New("not found")
+
+type memScheme struct {
+	mut   sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memScheme) Get(ctx context.Context, target *url.URL) (io.Reader, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	data, ok := m.files[target.Host+target.Path]
+	if !ok {
+		return nil, errMemNotFound
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (m *memScheme) Put(ctx context.Context, target *url.URL, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.files[target.Host+target.Path] = data
+	return target.String(), nil
+}
+
+func TestRegisterScheme(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	mem := &memScheme{files: map[string][]byte{}}
+	putter.RegisterScheme("mem", mem)
+	ctx := context.Background()
+
+	raw, err := putter.PutIn(ctx, "mem://bucket/a.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw != "mem://bucket/a.txt" {
+		t.Fatalf("got raw %q", raw)
+	}
+	got, err := putter.GetBytes(ctx, "mem://bucket/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+	_, err = putter.GetFrom(ctx, "mem://bucket/missing.txt")
+	if !errors.Is(err, errMemNotFound) {
+		t.Fatalf("got %v, want the error of the handler", err)
+	}
+
+	// it composes with the built-in schemes
+	r, err := putter.GetFrom(ctx, "mem://bucket/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitURI := "git://" + owner + "/repo/main/a.txt"
+	_, err = putter.PutIn(ctx, gitURI, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := putter.GetFromBatch(ctx, []string{"mem://bucket/a.txt", gitURI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("%s: %v", result.URI, result.Err)
+		}
+		got, err := io.ReadAll(result.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "content" {
+			t.Fatalf("%s: got %q, want %q", result.URI, got, "content")
+		}
+	}
+}
+
+func TestRegisterSchemeReplacesBuiltin(t *testing.T) {
+	_, putter := putinghtest.NewServer(t)
+	mem := &memScheme{files: map[string][]byte{}}
+	putter.RegisterScheme("git", mem)
+	ctx := context.Background()
+
+	uri := "git://" + owner + "/repo/main/a.txt"
+	_, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(mem.files[owner+"/repo/main/a.txt"]) != "content" {
+		t.Fatalf("the put did not go through the registered handler: %q", mem.files)
+	}
+}
@@ -0,0 +1,103 @@
+package putingh_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGitSignatureFromEnv(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "env author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "author@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "")
+	t.Setenv("GIT_COMMITTER_EMAIL", "")
+	srv, putter := putinghtest.NewServer(t, putingh.WithGitSignatureFromEnv(true))
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/name.txt", strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := headCommit(t, srv, "repo", "main")
+	if commit.Author.Name != "env author" || commit.Author.Email != "author@example.com" {
+		t.Fatalf("got author %s <%s>", commit.Author.Name, commit.Author.Email)
+	}
+	if commit.Committer.Name == "env author" || commit.Committer.Email == "author@example.com" {
+		t.Fatalf("the author variables set the committer %s <%s>", commit.Committer.Name, commit.Committer.Email)
+	}
+
+	t.Setenv("GIT_COMMITTER_NAME", "env committer")
+	t.Setenv("GIT_COMMITTER_EMAIL", "committer@example.com")
+	putter = newPutter(t, srv, putingh.WithGitSignatureFromEnv(true))
+	_, err = putter.PutIn(ctx, "git://"+owner+"/repo/main/name.txt", strings.NewReader("v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit = headCommit(t, srv, "repo", "main")
+	if commit.Author.Name != "env author" {
+		t.Fatalf("got author %s", commit.Author.Name)
+	}
+	if commit.Committer.Name != "env committer" || commit.Committer.Email != "committer@example.com" {
+		t.Fatalf("got committer %s <%s>", commit.Committer.Name, commit.Committer.Email)
+	}
+}
+
+func TestCommitSigner(t *testing.T) {
+	const armored = "-----BEGIN PGP SIGNATURE-----\n\nstub\n-----END PGP SIGNATURE-----\n"
+	var payload []byte
+	signer := func(data []byte) ([]byte, error) {
+		payload = data
+		return []byte(armored), nil
+	}
+	srv, putter := putinghtest.NewServer(t, putingh.WithCommitSigner(signer))
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/name.txt", strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := headCommit(t, srv, "repo", "main")
+	if commit.PGPSignature != armored {
+		t.Fatalf("got signature %q, want %q", commit.PGPSignature, armored)
+	}
+
+	// the signer gets the commit as it is stored, without the signature
+	obj := &plumbing.MemoryObject{}
+	err = commit.EncodeWithoutSignature(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := obj.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payload, want) {
+		t.Fatalf("signed payload %q, want %q", payload, want)
+	}
+}
+
+func TestCommitSignerError(t *testing.T) {
+	errSigner := errors.New("kms unavailable")
+	signer := func(data []byte) ([]byte, error) {
+		return nil, errSigner
+	}
+	srv, putter := putinghtest.NewServer(t, putingh.WithCommitSigner(signer))
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+	before := headCommit(t, srv, "repo", "main").Hash
+	_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", strings.NewReader("content"))
+	if !errors.Is(err, errSigner) {
+		t.Fatalf("got %v, want the signer error", err)
+	}
+	if after := headCommit(t, srv, "repo", "main").Hash; after != before {
+		t.Fatalf("pushed %s without a signature", after)
+	}
+}
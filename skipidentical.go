@@ -0,0 +1,44 @@
+package putingh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithAssetSkipIdentical leaves a release asset alone when it already holds the content being put,
+// and returns its URL instead of uploading it again, so publishing can be re-run.
+// Assets are compared by size, then by their checksum sidecar or else by downloading them.
+func WithAssetSkipIdentical(skip bool) Option {
+	return func(p *PutInGH) {
+		p.assetSkipIdentical = skip
+	}
+}
+
+// assetIdentical is assetUnchanged that trusts the checksum sidecar of asset when there is one.
+func (s *PutInGH) assetIdentical(ctx context.Context, assets []*ghv3.ReleaseAsset, asset *ghv3.ReleaseAsset, open func() (io.Reader, int64, error)) (bool, error) {
+	if open == nil {
+		return false, nil
+	}
+	want, err := s.assetChecksum(ctx, assets, asset)
+	if err != nil || want == "" {
+		return s.assetUnchanged(ctx, asset, open)
+	}
+	r, size, err := open()
+	if err != nil {
+		return false, err
+	}
+	defer closeReader(r)
+	if int64(asset.GetSize()) != size {
+		return false, nil
+	}
+	hash := sha256.New()
+	_, err = io.Copy(hash, r)
+	if err != nil {
+		return false, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)) == want, nil
+}
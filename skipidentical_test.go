@@ -0,0 +1,82 @@
+package putingh_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestAssetSkipIdentical(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	var uploads, downloads atomic.Int32
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/uploads/"):
+			uploads.Add(1)
+		case r.URL.Path == "/"+owner+"/repo/releases/download/v1/name.txt":
+			downloads.Add(1)
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	putter := newPutter(t, srv, putingh.WithAssetSkipIdentical(true))
+	ctx := context.Background()
+	uri := "asset://" + owner + "/repo/v1/name.txt"
+
+	first, err := putter.PutIn(ctx, uri, strings.NewReader("content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name    string
+		content string
+		upload  bool
+	}{
+		{name: "identical", content: "content"},
+		{name: "same size", content: "CONTENT", upload: true},
+		{name: "other size", content: "other content", upload: true},
+		{name: "identical again", content: "other content"},
+	} {
+		uploads.Store(0)
+		got, err := putter.PutIn(ctx, uri, strings.NewReader(tc.content))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if n := uploads.Load(); (n != 0) != tc.upload {
+			t.Fatalf("%s: uploaded %d times, want an upload %v", tc.name, n, tc.upload)
+		}
+		if tc.name == "identical" && got != first {
+			t.Fatalf("%s: got %q, want the URL of the existing asset %q", tc.name, got, first)
+		}
+		data, err := putter.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tc.content {
+			t.Fatalf("%s: got %q, want %q", tc.name, data, tc.content)
+		}
+	}
+
+	// with a sidecar the asset is compared without downloading it
+	sum := sha256.Sum256([]byte("other content"))
+	_, err = putter.PutIn(ctx, uri+".sha256", strings.NewReader(hex.EncodeToString(sum[:])+"  name.txt\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploads.Store(0)
+	downloads.Store(0)
+	_, err = putter.PutIn(ctx, uri, strings.NewReader("other content"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploads.Load() != 0 || downloads.Load() != 0 {
+		t.Fatalf("uploaded %d and downloaded %d times, want neither", uploads.Load(), downloads.Load())
+	}
+}
@@ -0,0 +1,201 @@
+package putingh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// WithSparseCheckout only materializes the directories paths in git worktrees, the index
+// still holds every file so commits keep the rest of the tree.
+// Files outside paths are read from the object store instead of the worktree,
+// and a full checkout is done when the sparse one fails.
+func WithSparseCheckout(paths []string) Option {
+	return func(p *PutInGH) {
+		p.sparseCheckout = paths
+	}
+}
+
+// resetGit hard resets the worktree to hash, sparsely when WithSparseCheckout is set.
+func (s *PutInGH) resetGit(repository *gogit.Repository, work *gogit.Worktree, hash plumbing.Hash) error {
+	opts := &gogit.ResetOptions{
+		Commit: hash,
+		Mode:   gogit.HardReset,
+	}
+	if len(s.sparseCheckout) == 0 {
+		return work.Reset(opts)
+	}
+	err := s.resetGitSparsely(repository, work, hash)
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintf(s.out, "sparse checkout: %v, falling back to a full checkout\n", err)
+
+	// go-git drops skip-worktree entries on reset instead of checking them out
+	idx, err := repository.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for _, entry := range idx.Entries {
+		entry.SkipWorktree = false
+	}
+	err = repository.Storer.SetIndex(idx)
+	if err != nil {
+		return err
+	}
+	return work.Reset(opts)
+}
+
+// resetGitSparsely rebuilds the index from the tree of hash, files outside the sparse paths
+// are marked skip-worktree and removed from the worktree.
+// Worktree.ResetSparsely is not used, it loses skipped entries on the next reset.
+func (s *PutInGH) resetGitSparsely(repository *gogit.Repository, work *gogit.Worktree, hash plumbing.Hash) error {
+	commit, err := repository.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	old, err := repository.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	idx := &index.Index{Version: 3}
+	checkedOut := map[string]bool{}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		entry := idx.Add(f.Name)
+		entry.Hash = f.Hash
+		entry.Mode = f.Mode
+		if !s.inSparseCheckout(f.Name) {
+			entry.SkipWorktree = true
+			return nil
+		}
+		checkedOut[f.Name] = true
+		if o, err := old.Entry(f.Name); err == nil && !o.SkipWorktree && o.Hash == f.Hash && o.Mode == f.Mode {
+			fi, err := work.Filesystem.Lstat(f.Name)
+			if err == nil && fi.ModTime().Equal(o.ModifiedAt) && uint32(fi.Size()) == o.Size {
+				entry.ModifiedAt = o.ModifiedAt
+				entry.Size = o.Size
+				return nil
+			}
+		}
+		err := checkoutGitFile(work, f)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, f.Name)
+		}
+		fi, err := work.Filesystem.Lstat(f.Name)
+		if err != nil {
+			return err
+		}
+		entry.ModifiedAt = fi.ModTime()
+		entry.Size = uint32(fi.Size())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, o := range old.Entries {
+		if checkedOut[o.Name] || o.SkipWorktree {
+			continue
+		}
+		err = work.Filesystem.Remove(o.Name)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removeEmptyDirs(work, path.Dir(o.Name))
+	}
+	return repository.Storer.SetIndex(idx)
+}
+
+func (s *PutInGH) inSparseCheckout(name string) bool {
+	for _, p := range s.sparseCheckout {
+		p = strings.Trim(p, "/")
+		if p == "" || name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func checkoutGitFile(work *gogit.Worktree, f *object.File) error {
+	mode, err := f.Mode.ToOSFileMode()
+	if err != nil {
+		return err
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	err = work.Filesystem.Remove(f.Name)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if mode&os.ModeSymlink != 0 {
+		target, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return work.Filesystem.Symlink(string(target), f.Name)
+	}
+	w, err := work.Filesystem.OpenFile(f.Name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// removeEmptyDirs removes dir and its parents inside the worktree while they are empty.
+func removeEmptyDirs(work *gogit.Worktree, dir string) {
+	for dir != "." && dir != "/" && dir != "" {
+		entries, err := work.Filesystem.ReadDir(dir)
+		if err != nil || len(entries) != 0 {
+			return
+		}
+		if work.Filesystem.Remove(dir) != nil {
+			return
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// readGitHeadFile reads name from the HEAD commit, for files skipped by a sparse checkout.
+func readGitHeadFile(repository *gogit.Repository, name string) (io.Reader, error) {
+	head, err := repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(name)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	r, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	return newReaderWithAutoCloser(r), nil
+}
@@ -0,0 +1,80 @@
+package putingh_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestSparseCheckout(t *testing.T) {
+	tmp := t.TempDir()
+	srv, _ := putinghtest.NewServer(t)
+	files := map[string]string{
+		"README.md":        "readme",
+		"configs/a.yaml":   "a",
+		"docs/guide.md":    "guide",
+		"docs/deep/ref.md": "ref",
+	}
+	pushGit(t, srv, "repo", "main", files)
+	putter := newPutter(t, srv, putingh.WithTmpDir(tmp), putingh.WithSparseCheckout([]string{"configs/"}))
+	ctx := context.Background()
+
+	writes := map[string]string{
+		"configs/b.yaml": "b",
+		// outside of the sparse paths, nothing is lost from the tree
+		"docs/new.md": "new",
+	}
+	for name, content := range writes {
+		_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/"+name, strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = content
+	}
+
+	worktree := filepath.Join(tmp, "git", owner, "repo", "main")
+	// the next put resets the worktree, dropping docs/new.md that was written outside of the sparse paths
+	_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/configs/c.yaml", strings.NewReader("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["configs/c.yaml"] = "c"
+	for name := range files {
+		_, err := os.Lstat(filepath.Join(worktree, filepath.FromSlash(name)))
+		materialized := err == nil
+		if materialized != strings.HasPrefix(name, "configs/") {
+			t.Errorf("%s: materialized is %v", name, materialized)
+		}
+	}
+
+	tree, err := headCommit(t, srv, "repo", "main").Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		file, err := tree.File(name)
+		if err != nil {
+			t.Fatalf("%s is missing from the tree: %v", name, err)
+		}
+		got, err := file.Contents()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("%s: got %q in the tree, want %q", name, got, want)
+		}
+
+		read, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/"+name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(read) != want {
+			t.Fatalf("%s: read %q, want %q", name, read, want)
+		}
+	}
+}
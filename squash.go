@@ -0,0 +1,90 @@
+package putingh
+
+import (
+	"fmt"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// WithForcePush force pushes git writes, discarding remote commits missing from the local branch.
+func WithForcePush(force bool) Option {
+	return func(p *PutInGH) {
+		p.forcePush = force
+	}
+}
+
+// WithAutoSquash keeps only the last keepLast commits of a branch on every git write,
+// the oldest kept commit becomes the root holding the squashed history.
+// This rewrites and force pushes the branch, so anything based on the old history is orphaned.
+func WithAutoSquash(keepLast int) Option {
+	return func(p *PutInGH) {
+		p.autoSquash = keepLast
+	}
+}
+
+// pushForce squashes branch when WithAutoSquash is set and reports whether its push must be forced.
+func (s *PutInGH) pushForce(repository *gogit.Repository, branch string) (bool, error) {
+	if s.autoSquash <= 0 {
+		return s.forcePush, nil
+	}
+	squashed, err := s.squashGit(repository, branch)
+	if err != nil {
+		return false, fmt.Errorf("git squash: %w", err)
+	}
+	return s.forcePush || squashed, nil
+}
+
+// squashGit rewrites branch to its last autoSquash first-parent commits and reports whether it changed.
+func (s *PutInGH) squashGit(repository *gogit.Repository, branch string) (bool, error) {
+	refName := plumbing.NewBranchReferenceName(branch)
+	ref, err := repository.Reference(refName, true)
+	if err != nil {
+		return false, err
+	}
+	commit, err := repository.CommitObject(ref.Hash())
+	if err != nil {
+		return false, err
+	}
+	commits := []*object.Commit{commit}
+	for len(commits) < s.autoSquash && commit.NumParents() != 0 {
+		commit, err = commit.Parent(0)
+		if err != nil {
+			return false, err
+		}
+		commits = append(commits, commit)
+	}
+	if commits[len(commits)-1].NumParents() == 0 {
+		return false, nil
+	}
+
+	var parents []plumbing.Hash
+	var hash plumbing.Hash
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		rewritten := &object.Commit{
+			Author:       c.Author,
+			Committer:    c.Committer,
+			Message:      c.Message,
+			TreeHash:     c.TreeHash,
+			ParentHashes: parents,
+		}
+		if s.commitSigner != nil {
+			err = s.sign(rewritten)
+			if err != nil {
+				return false, err
+			}
+		}
+		hash, err = storeCommit(repository, rewritten)
+		if err != nil {
+			return false, err
+		}
+		parents = []plumbing.Hash{hash}
+	}
+	err = repository.Storer.SetReference(plumbing.NewHashReference(refName, hash))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package putingh_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestAutoSquash(t *testing.T) {
+	for _, keepLast := range []int{1, 3} {
+		t.Run(strconv.Itoa(keepLast), func(t *testing.T) {
+			srv, putter := putinghtest.NewServer(t, putingh.WithAutoSquash(keepLast))
+			ctx := context.Background()
+			for i := 1; i <= 6; i++ {
+				_, err := putter.PutIn(ctx, "git://"+owner+"/repo/main/name.txt", strings.NewReader("v"+strconv.Itoa(i)))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			history := []*object.Commit{}
+			commit := headCommit(t, srv, "repo", "main")
+			for {
+				history = append(history, commit)
+				if commit.NumParents() == 0 {
+					break
+				}
+				parent, err := commit.Parent(0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				commit = parent
+			}
+			if len(history) != keepLast {
+				t.Fatalf("got %d commits, want %d", len(history), keepLast)
+			}
+			// the root holds the content as of the oldest kept write
+			for i, commit := range history {
+				file, err := commit.File("name.txt")
+				if err != nil {
+					t.Fatal(err)
+				}
+				content, err := file.Contents()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := "v" + strconv.Itoa(6-i); content != want {
+					t.Fatalf("commit %d back has %q, want %q", i, content, want)
+				}
+			}
+		})
+	}
+}
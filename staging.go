@@ -0,0 +1,49 @@
+package putingh
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WithAssetStagingDir stages assets that need a temp file under dir instead of the asset directory in tmpDir.
+func WithAssetStagingDir(dir string) Option {
+	return func(p *PutInGH) {
+		p.assetStagingDir = dir
+	}
+}
+
+// WithAssetStagingFunc creates the temp file assets are staged in with fn, which gets the asset name.
+// The file is uploaded by its name after it is closed, and the returned func is called once that is done.
+func WithAssetStagingFunc(fn func(name string) (*os.File, func(), error)) Option {
+	return func(p *PutInGH) {
+		p.assetStagingFunc = fn
+	}
+}
+
+// stageAsset returns the temp file to stage name in and the func that removes it.
+func (s *PutInGH) stageAsset(owner, repo, release, name string) (*os.File, func(), error) {
+	if s.assetStagingFunc != nil {
+		f, cleanup, err := s.assetStagingFunc(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cleanup == nil {
+			cleanup = func() {}
+		}
+		return f, cleanup, nil
+	}
+	dir := s.assetStagingDir
+	if dir == "" {
+		dir = filepath.Join(s.tmpDir, "asset")
+	}
+	filename, err := safeJoin(dir, owner, repo, release, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	os.MkdirAll(filepath.Dir(filename), 0755)
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { os.Remove(filename) }, nil
+}
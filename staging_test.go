@@ -0,0 +1,90 @@
+package putingh_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestAssetStagingDir(t *testing.T) {
+	tmp := t.TempDir()
+	stage := t.TempDir()
+	srv, _ := putinghtest.NewServer(t)
+	staged := filepath.Join(stage, owner, "repo", "v1", "name.bin")
+	var stagedDuringUpload bool
+	handler := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/uploads/") {
+			_, err := os.Stat(staged)
+			stagedDuringUpload = err == nil
+		}
+		handler.ServeHTTP(rw, r)
+	})
+	putter := newPutter(t, srv, putingh.WithTmpDir(tmp), putingh.WithAssetStagingDir(stage))
+	ctx := context.Background()
+	uri := "asset://" + owner + "/repo/v1/name.bin"
+	// a reader of unknown length is staged
+	_, err := putter.PutIn(ctx, uri, struct{ io.Reader }{strings.NewReader("content")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stagedDuringUpload {
+		t.Fatalf("%s was not staged for the upload", staged)
+	}
+	if _, err := os.Stat(staged); !os.IsNotExist(err) {
+		t.Fatalf("%s is left after the upload: %v", staged, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "asset")); !os.IsNotExist(err) {
+		t.Fatalf("staged in the tmp dir: %v", err)
+	}
+	got, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+}
+
+func TestAssetStagingFunc(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	var names []string
+	cleanups := 0
+	putter := newPutter(t, srv, putingh.WithAssetStagingFunc(func(name string) (*os.File, func(), error) {
+		names = append(names, name)
+		f, err := os.CreateTemp(t.TempDir(), "staged-*")
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, func() {
+			cleanups++
+			os.Remove(f.Name())
+		}, nil
+	}))
+	ctx := context.Background()
+	uri := "asset://" + owner + "/repo/v1/name.bin"
+	_, err := putter.PutIn(ctx, uri, struct{ io.Reader }{strings.NewReader("content")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "name.bin" {
+		t.Fatalf("staged %v, want name.bin", names)
+	}
+	if cleanups != 1 {
+		t.Fatalf("cleaned up %d times, want once", cleanups)
+	}
+	got, err := putter.GetBytes(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("got %q, want %q", got, "content")
+	}
+}
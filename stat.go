@@ -0,0 +1,148 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// ObjectInfo describes the content behind a uri.
+type ObjectInfo struct {
+	Name string
+	Size int64
+	// ContentType and UpdatedAt are only known for assets.
+	ContentType string
+	UpdatedAt   time.Time
+}
+
+// Stat returns the size of the content behind uri without downloading it, ErrNotFound when it is absent.
+func (s *PutInGH) Stat(ctx context.Context, uri string) (*ObjectInfo, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.operationContext(ctx)
+	if u.Scheme == "http" || u.Scheme == "https" {
+		gistURI, err := s.resolveGistURL(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		return s.Stat(ctx, gistURI)
+	}
+	err = s.checkURIAllowed(u)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.schemeContext(ctx, u.Scheme)
+	defer cancel()
+	switch u.Scheme {
+	case "git":
+		repo, branch, name, ok := splitGitPath(u.Path)
+		if !ok {
+			return nil, fmt.Errorf("%q not match git://owner/repository/branch/name", uri)
+		}
+		return s.statGit(ctx, u.Host, repo, branch, name)
+	case "asset":
+		sl := strings.SplitN(u.Path, "/", 4)
+		if len(sl) != 4 {
+			return nil, fmt.Errorf("%q not match asset://owner/repository/release/name", uri)
+		}
+		release, err := s.getRelease(ctx, u.Host, sl[1], sl[2])
+		if err != nil {
+			return nil, err
+		}
+		asset, err := s.matchAsset(release.Assets, sl[3])
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, sl[3])
+			}
+			return nil, err
+		}
+		return &ObjectInfo{
+			Name:        asset.GetName(),
+			Size:        int64(asset.GetSize()),
+			ContentType: asset.GetContentType(),
+			UpdatedAt:   asset.GetUpdatedAt().Time,
+		}, nil
+	case "gist":
+		sl := strings.SplitN(u.Path, "/", 3)
+		if len(sl) != 3 {
+			return nil, fmt.Errorf("%q not match gist://owner/gist_id/name", uri)
+		}
+		gist, err := s.findGist(ctx, u.Host, sl[1], sl[2])
+		if err != nil {
+			return nil, err
+		}
+		if gist == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, sl[1])
+		}
+		file, ok := gist.Files[ghv3.GistFilename(sl[2])]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, sl[2])
+		}
+		return &ObjectInfo{
+			Name: sl[2],
+			Size: int64(file.GetSize()),
+		}, nil
+	}
+	return nil, fmt.Errorf("%q not support", uri)
+}
+
+func (s *PutInGH) statGit(ctx context.Context, owner, repo, branch, name string) (*ObjectInfo, error) {
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	fname, err := safeJoin(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Lstat(fname)
+	if err == nil {
+		if fi.IsDir() {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return &ObjectInfo{
+			Name: name,
+			Size: fi.Size(),
+		}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(s.sparseCheckout) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	// skipped by the sparse checkout
+	head, err := repository.Head()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return &ObjectInfo{
+		Name: name,
+		Size: file.Size,
+	}, nil
+}
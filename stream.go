@@ -0,0 +1,179 @@
+package putingh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/filesystem/dotgit"
+)
+
+// largeObjectThreshold is the size above which git objects are read lazily instead of loaded into memory.
+const largeObjectThreshold = 1 << 20
+
+func newGitStorage(dot billy.Filesystem) *filesystem.Storage {
+	return filesystem.NewStorageWithOptions(dot, cache.NewObjectLRUDefault(), filesystem.Options{
+		LargeObjectThreshold: largeObjectThreshold,
+	})
+}
+
+func plainInitGit(dir string) (*gogit.Repository, error) {
+	wt := osfs.New(dir)
+	dot, err := wt.Chroot(gogit.GitDirName)
+	if err != nil {
+		return nil, err
+	}
+	return gogit.Init(newGitStorage(dot), wt)
+}
+
+func plainOpenGit(dir string) (*gogit.Repository, error) {
+	wt := osfs.New(dir)
+	dot, err := wt.Chroot(gogit.GitDirName)
+	if err != nil {
+		return nil, err
+	}
+	_, err = dot.Stat("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, gogit.ErrRepositoryNotExists
+		}
+		return nil, err
+	}
+	return gogit.Open(newGitStorage(dot), wt)
+}
+
+// plainOpenBareGit opens the bare repository in dir, or the repository of the worktree in dir.
+func plainOpenBareGit(dir string) (*gogit.Repository, error) {
+	fi, err := os.Stat(filepath.Join(dir, gogit.GitDirName))
+	if err == nil && fi.IsDir() {
+		return plainOpenGit(dir)
+	}
+	dot := osfs.New(dir)
+	_, err = dot.Stat("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, gogit.ErrRepositoryNotExists
+		}
+		return nil, err
+	}
+	return gogit.Open(newGitStorage(dot), nil)
+}
+
+// writeBlob stores size bytes of r as a blob, streamed into a loose object when the repository is on disk.
+func writeBlob(repository *gogit.Repository, r io.Reader, size int64) (plumbing.Hash, error) {
+	st, ok := repository.Storer.(*filesystem.Storage)
+	if !ok {
+		obj := repository.Storer.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		obj.SetSize(size)
+		w, err := obj.Writer()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		_, err = io.CopyN(w, r, size)
+		if err != nil {
+			w.Close()
+			return plumbing.ZeroHash, err
+		}
+		err = w.Close()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return repository.Storer.SetEncodedObject(obj)
+	}
+
+	w, err := dotgit.New(st.Filesystem()).NewObject()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	err = w.WriteHeader(plumbing.BlobObject, size)
+	if err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	_, err = io.CopyN(w, r, size)
+	if err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	err = w.Close()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return w.Hash(), nil
+}
+
+// writeBlobFromReader stores r as a blob, spooling it to a temp file first to learn its size.
+func (s *PutInGH) writeBlobFromReader(repository *gogit.Repository, r io.Reader) (plumbing.Hash, error) {
+	err := os.MkdirAll(s.tmpDir, 0755)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	f, err := os.CreateTemp(s.tmpDir, "blob-*")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	size, err := io.Copy(f, r)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return writeBlob(repository, f, size)
+}
+
+// addGitFile stages name like Worktree.Add, which holds the whole file in memory,
+// but streams regular files into the object store.
+func addGitFile(repository *gogit.Repository, work *gogit.Worktree, name string) error {
+	fi, err := work.Filesystem.Lstat(name)
+	if err != nil || !fi.Mode().IsRegular() {
+		_, err = work.Add(name)
+		return err
+	}
+	f, err := work.Filesystem.Open(name)
+	if err != nil {
+		return err
+	}
+	hash, err := writeBlob(repository, f, fi.Size())
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	idx, err := repository.Storer.Index()
+	if err != nil {
+		return err
+	}
+	entry, err := idx.Entry(name)
+	if err != nil {
+		if !errors.Is(err, index.ErrEntryNotFound) {
+			return err
+		}
+		entry = idx.Add(name)
+	}
+	entry.Hash = hash
+	entry.SkipWorktree = false
+	entry.ModifiedAt = fi.ModTime()
+	entry.Mode, err = filemode.NewFromOSFileMode(fi.Mode())
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, name)
+	}
+	entry.Size = uint32(fi.Size())
+	return repository.Storer.SetIndex(idx)
+}
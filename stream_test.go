@@ -0,0 +1,70 @@
+package putingh_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+// patternReader yields size bytes of a repeating pattern without holding them in memory.
+type patternReader struct {
+	left int64
+}
+
+func (p *patternReader) Read(b []byte) (int, error) {
+	if p.left <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(b)) > p.left {
+		b = b[:p.left]
+	}
+	for i := range b {
+		b[i] = "0123456789abcdef"[i%16]
+	}
+	p.left -= int64(len(b))
+	return len(b), nil
+}
+
+// BenchmarkGitLargeFile writes and reads back a large git file, the bytes allocated per op stay flat as the size grows.
+// The file is pushed once up front, as the fake server keeps its repositories in memory,
+// so the writes measured are of unchanged content while reads fetch nothing new.
+func BenchmarkGitLargeFile(b *testing.B) {
+	for _, size := range []int64{1 << 20, 16 << 20, 64 << 20} {
+		_, putter := putinghtest.NewServer(b)
+		ctx := context.Background()
+		uri := "git://" + owner + "/repo/main/large.bin"
+		_, err := putter.PutIn(ctx, uri, &patternReader{left: size})
+		if err != nil {
+			b.Fatal(err)
+		}
+		name := strconv.FormatInt(size>>20, 10) + "MiB"
+
+		b.Run("write/"+name, func(b *testing.B) {
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := putter.PutIn(ctx, uri, &patternReader{left: size})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("read/"+name, func(b *testing.B) {
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r, err := putter.GetFrom(ctx, uri)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, err = io.Copy(io.Discard, r)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
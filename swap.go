@@ -0,0 +1,100 @@
+package putingh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SwapGit exchanges the contents of nameA and nameB in a single commit and returns the commit sha of branch.
+func (s *PutInGH) SwapGit(ctx context.Context, owner, repo, branch, nameA, nameB string) (string, error) {
+	if err := s.checkWritable(); err != nil {
+		return "", err
+	}
+	if err := s.checkAllowed("git", owner, repo); err != nil {
+		return "", err
+	}
+	ctx = s.operationContext(ctx)
+	branch, err := s.resolveBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return "", err
+	}
+	unlock, err := s.lockWorktree(ctx, owner, repo, branch)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	dir, repository, err := s.fetchGit(ctx, owner, repo, branch)
+	if err != nil {
+		return "", err
+	}
+	err = s.swapGitFiles(dir, nameA, nameB)
+	if err != nil {
+		return "", err
+	}
+	_, _, err = s.commitGit(ctx, repository, owner, repo, branch, nameA+", "+nameB, dir, []string{nameA, nameB})
+	if err != nil {
+		return "", err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// swapGitFiles exchanges the files through a temp copy of nameA, so neither is held in memory.
+func (s *PutInGH) swapGitFiles(dir, nameA, nameB string) error {
+	a, err := s.openGitFile(dir, nameA)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(s.tmpDir, 0755)
+	if err != nil {
+		closeReader(a)
+		return err
+	}
+	tmp, err := os.CreateTemp(s.tmpDir, "swap-*")
+	if err != nil {
+		closeReader(a)
+		return err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+	_, err = io.Copy(tmp, a)
+	closeReader(a)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	b, err := s.openGitFile(dir, nameB)
+	if err != nil {
+		return err
+	}
+	_, err = s.writeGitFile(dir, nameA, b)
+	closeReader(b)
+	if err != nil {
+		return err
+	}
+	_, err = s.writeGitFile(dir, nameB, tmp)
+	return err
+}
+
+func (s *PutInGH) openGitFile(dir, name string) (io.Reader, error) {
+	r, err := s.readGitFile(dir, name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	return r, nil
+}
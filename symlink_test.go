@@ -0,0 +1,50 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestGetFromGitSymlinks(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	deep := strings.Repeat("../", 16) + "etc/passwd"
+	pushGit(t, srv, "repo", "main", map[string]string{
+		"target.txt":  "inside",
+		"inside.txt":  "symlink:target.txt",
+		"passwd":      "symlink:../../etc/passwd",
+		"deep/passwd": "symlink:" + deep,
+	})
+	ctx := context.Background()
+
+	got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/inside.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "inside" {
+		t.Fatalf("got %q, want the link target content", got)
+	}
+	for _, name := range []string{"passwd", "deep/passwd"} {
+		got, err := putter.GetBytes(ctx, "git://"+owner+"/repo/main/"+name)
+		if err == nil {
+			t.Fatalf("%s: read %d bytes through a symlink out of the worktree", name, len(got))
+		}
+	}
+	_, err = putter.GetBytes(ctx, "git://"+owner+"/repo/main/deep/passwd")
+	if !errors.Is(err, putingh.ErrInvalidPath) {
+		t.Fatalf("got %v, want ErrInvalidPath", err)
+	}
+
+	links := newPutter(t, srv, putingh.WithReadSymlinkTargets(false))
+	got, err = links.GetBytes(ctx, "git://"+owner+"/repo/main/deep/passwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != deep {
+		t.Fatalf("got %q, want the link text %q", got, deep)
+	}
+}
@@ -0,0 +1,59 @@
+package putingh
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// WithTimeout bounds every GetFrom, PutIn and PutInWithFile call,
+// a reader returned by GetFrom must be read within it as well.
+func WithTimeout(d time.Duration) Option {
+	return func(p *PutInGH) {
+		p.timeout = d
+	}
+}
+
+// WithSchemeTimeout bounds the calls for one scheme such as git, asset or gist,
+// it takes precedence over WithTimeout for that scheme.
+func WithSchemeTimeout(scheme string, d time.Duration) Option {
+	return func(p *PutInGH) {
+		if p.schemeTimeouts == nil {
+			p.schemeTimeouts = map[string]time.Duration{}
+		}
+		p.schemeTimeouts[scheme] = d
+	}
+}
+
+func (s *PutInGH) schemeContext(ctx context.Context, scheme string) (context.Context, context.CancelFunc) {
+	d, ok := s.schemeTimeouts[scheme]
+	if !ok {
+		d = s.timeout
+	}
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// newReaderWithCancel releases the context of r once r is drained or closed.
+func newReaderWithCancel(r io.Reader, cancel context.CancelFunc) io.Reader {
+	return newReaderWithAutoCloser(&readCloser{
+		Reader: r,
+		closers: []io.Closer{
+			closerFunc(func() error {
+				return closeReader(r)
+			}),
+			closerFunc(func() error {
+				cancel()
+				return nil
+			}),
+		},
+	})
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
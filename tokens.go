@@ -0,0 +1,81 @@
+package putingh
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"golang.org/x/oauth2"
+)
+
+// NewPutInGHMultiToken returns a PutInGH that rotates through tokens, one per operation,
+// so that the rate limit is spread across them.
+// All API, download and git requests of an operation use the same token.
+func NewPutInGHMultiToken(tokens []string, options ...Option) *PutInGH {
+	var token string
+	if len(tokens) != 0 {
+		token = tokens[0]
+	}
+	return NewPutInGH(token, append([]Option{withTokens(tokens)}, options...)...)
+}
+
+func withTokens(tokens []string) Option {
+	return func(p *PutInGH) {
+		p.tokens = tokens
+	}
+}
+
+type tokenContextKey struct{}
+
+// operationContext pins the next token to ctx, unless ctx already carries one.
+func (s *PutInGH) operationContext(ctx context.Context) context.Context {
+	if len(s.tokens) < 2 {
+		return ctx
+	}
+	if _, ok := ctx.Value(tokenContextKey{}).(string); ok {
+		return ctx
+	}
+	n := atomic.AddUint64(&s.tokenNext, 1) - 1
+	return context.WithValue(ctx, tokenContextKey{}, s.tokens[n%uint64(len(s.tokens))])
+}
+
+// tokenOf returns the token pinned to ctx or the default token.
+func (s *PutInGH) tokenOf(ctx context.Context) string {
+	if token, ok := ctx.Value(tokenContextKey{}).(string); ok {
+		return token
+	}
+	return s.token
+}
+
+// tokenTransport authenticates with the token pinned to the request context,
+// falling back to the oauth2 transport.
+type tokenTransport struct {
+	oauth *oauth2.Transport
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, ok := req.Context().Value(tokenContextKey{}).(string)
+	if !ok {
+		return t.oauth.RoundTrip(req)
+	}
+	base := t.oauth.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	(&oauth2.Token{AccessToken: token}).SetAuthHeader(req)
+	return base.RoundTrip(req)
+}
+
+func (s *PutInGH) withTokenTransport(rt http.RoundTripper) http.RoundTripper {
+	if len(s.tokens) < 2 {
+		return rt
+	}
+	t, ok := rt.(*oauth2.Transport)
+	if !ok {
+		return rt
+	}
+	return &tokenTransport{
+		oauth: t,
+	}
+}
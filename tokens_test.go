@@ -0,0 +1,73 @@
+package putingh_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestMultiTokenRotatesPerOperation(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	var mu sync.Mutex
+	used := map[string]bool{}
+	next := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, password, ok := r.BasicAuth(); ok {
+			token = password
+		}
+		mu.Lock()
+		used[token] = true
+		mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+	putter := putingh.NewPutInGHMultiToken([]string{"token-a", "token-b"},
+		putingh.WithHost(srv.URL),
+		putingh.WithTmpDir(t.TempDir()),
+	)
+
+	ctx := context.Background()
+	local := filepath.Join(t.TempDir(), "name.txt")
+	err := os.WriteFile(local, []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uri := range []string{"asset://" + owner + "/repo/v1/a.txt", "asset://" + owner + "/repo/v1/b.txt"} {
+		_, err = putter.PutInWithFile(ctx, uri, local)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !used["token-a"] || !used["token-b"] {
+		t.Fatalf("PutInWithFile used tokens %v, want both", used)
+	}
+
+	used = map[string]bool{}
+	for _, uri := range []string{"asset://" + owner + "/repo/v1/a.txt", "asset://" + owner + "/repo/v1/b.txt"} {
+		_, err = putter.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !used["token-a"] || !used["token-b"] {
+		t.Fatalf("GetFrom used tokens %v, want both", used)
+	}
+
+	used = map[string]bool{}
+	for _, uri := range []string{"asset://" + owner + "/repo/v1/a.txt", "asset://" + owner + "/repo/v1/b.txt"} {
+		_, err = putter.Stat(ctx, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !used["token-a"] || !used["token-b"] {
+		t.Fatalf("Stat used tokens %v, want both", used)
+	}
+}
@@ -0,0 +1,46 @@
+package putingh_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestCommitTrailers(t *testing.T) {
+	var got []string
+	trailers := func(owner, repo, branch, name string) map[string]string {
+		got = []string{owner, repo, branch, name}
+		return map[string]string{
+			"Signed-off-by": "Author <author@example.com>",
+			"Reviewed-by":   "Reviewer <reviewer@example.com>",
+		}
+	}
+	for message, want := range map[string]string{
+		"Update name.txt\n": "Update name.txt\n\n" +
+			"Reviewed-by: Reviewer <reviewer@example.com>\n" +
+			"Signed-off-by: Author <author@example.com>\n",
+		"Update name.txt\n\nWith a body": "Update name.txt\n\nWith a body\n\n" +
+			"Reviewed-by: Reviewer <reviewer@example.com>\n" +
+			"Signed-off-by: Author <author@example.com>\n",
+	} {
+		srv, putter := putinghtest.NewServer(t,
+			putingh.WithGitCommitMessage(func(owner, repo, branch, name, path string) string {
+				return message
+			}),
+			putingh.WithCommitTrailers(trailers),
+		)
+		_, err := putter.PutIn(context.Background(), "git://"+owner+"/repo/main/name.txt", strings.NewReader("content"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit := headCommit(t, srv, "repo", "main"); commit.Message != want {
+			t.Fatalf("got message %q, want %q", commit.Message, want)
+		}
+		if strings.Join(got, " ") != owner+" repo main name.txt" {
+			t.Fatalf("trailers called with %q", got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// WithProgress reports the bytes of an upload sent so far out of total.
+func WithProgress(fn func(name string, sent, total int64)) Option {
+	return func(p *PutInGH) {
+		p.progress = fn
+	}
+}
+
+// uploadReleaseAsset uploads f as the asset name, reporting the progress if configured.
+func (s *PutInGH) uploadReleaseAsset(ctx context.Context, owner, repo string, releaseID int64, name string, f *os.File) (*ghv3.ReleaseAsset, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("the asset to upload can't be a directory")
+	}
+	return s.uploadReleaseAssetReader(ctx, owner, repo, releaseID, name, f, stat.Size(), mime.TypeByExtension(filepath.Ext(f.Name())))
+}
+
+// uploadReleaseAssetReader uploads size bytes of r as the asset name.
+func (s *PutInGH) uploadReleaseAssetReader(ctx context.Context, owner, repo string, releaseID int64, name string, r io.Reader, size int64, mediaType string) (*ghv3.ReleaseAsset, error) {
+	if s.progress != nil {
+		r = &progressReader{
+			r:     r,
+			name:  name,
+			total: size,
+			fn:    s.progress,
+		}
+	}
+	u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?name=%s", owner, repo, releaseID, url.QueryEscape(name))
+	req, err := s.cliv3.NewUploadRequest(u, r, size, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	asset := &ghv3.ReleaseAsset{}
+	_, err = s.cliv3.Do(ctx, req, asset)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+type progressReader struct {
+	r     io.Reader
+	name  string
+	sent  int64
+	total int64
+	fn    func(name string, sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.fn(p.name, p.sent, p.total)
+	}
+	return n, err
+}
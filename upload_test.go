@@ -0,0 +1,63 @@
+package putingh_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestProgress(t *testing.T) {
+	content := strings.Repeat("0123456789abcdef", 1<<12)
+	for name, r := range map[string]func() io.Reader{
+		"staged": func() io.Reader {
+			return struct{ io.Reader }{strings.NewReader(content)}
+		},
+		"direct": func() io.Reader {
+			return strings.NewReader(content)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv, _ := putinghtest.NewServer(t)
+			var contentLength int64 = -1
+			handler := srv.Config.Handler
+			srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, "/api/uploads/") {
+					contentLength = r.ContentLength
+				}
+				handler.ServeHTTP(rw, r)
+			})
+
+			var calls int
+			var sent, total int64
+			putter := newPutter(t, srv, putingh.WithProgress(func(name string, s, tot int64) {
+				if name != "name.bin" {
+					t.Errorf("got progress of %q", name)
+				}
+				if s < sent {
+					t.Errorf("sent went back from %d to %d", sent, s)
+				}
+				calls++
+				sent, total = s, tot
+			}))
+			_, err := putter.PutIn(context.Background(), "asset://"+owner+"/repo/v1/name.bin", r())
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := int64(len(content))
+			if calls == 0 {
+				t.Fatal("progress was not reported")
+			}
+			if sent != size || total != size {
+				t.Fatalf("got %d of %d sent, want %d", sent, total, size)
+			}
+			if contentLength != size {
+				t.Fatalf("uploaded with Content-Length %d, want %d", contentLength, size)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestErrorsCarryURI(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	pushGit(t, srv, "repo", "main", map[string]string{"README.md": "readme"})
+	readOnly := newPutter(t, srv, putingh.WithReadOnly(true))
+	ctx := context.Background()
+	fname := filepath.Join(t.TempDir(), "name.txt")
+	err := os.WriteFile(fname, []byte("content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		uri  string
+		want error
+		call func(uri string) error
+	}{
+		{
+			name: "GetFrom",
+			uri:  "git://" + owner + "/repo/main/missing.txt",
+			want: os.ErrNotExist,
+			call: func(uri string) error {
+				_, err := putter.GetFrom(ctx, uri)
+				return err
+			},
+		},
+		{
+			name: "GetBytes",
+			uri:  "gist://" + owner + "/missing/name.txt",
+			want: putingh.ErrNotFound,
+			call: func(uri string) error {
+				_, err := putter.GetBytes(ctx, uri)
+				return err
+			},
+		},
+		{
+			name: "PutIn",
+			uri:  "git://" + owner + "/repo/main/name.txt",
+			want: putingh.ErrReadOnly,
+			call: func(uri string) error {
+				_, err := readOnly.PutIn(ctx, uri, strings.NewReader("content"))
+				return err
+			},
+		},
+		{
+			name: "PutInWithFile",
+			uri:  "asset://" + owner + "/repo/v1/name.txt",
+			want: putingh.ErrReadOnly,
+			call: func(uri string) error {
+				_, err := readOnly.PutInWithFile(ctx, uri, fname)
+				return err
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call(tc.uri)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+			var pathErr *os.PathError
+			if tc.want == os.ErrNotExist && !errors.As(err, &pathErr) {
+				t.Fatalf("got %v, want an *os.PathError", err)
+			}
+			if n := strings.Count(err.Error(), tc.uri); n != 1 {
+				t.Fatalf("got %q, want the URI once", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// URLs returns the raw content URL and the human-facing HTML URL of uri without any API call.
+func (s *PutInGH) URLs(uri string) (raw, html string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", err
+	}
+	switch u.Scheme {
+	case "git":
+		repo, branch, name, ok := splitGitPath(u.Path)
+		if !ok {
+			return "", "", fmt.Errorf("%q not match git://owner/repository/branch/name", uri)
+		}
+		base := s.gitURL(u.Host, repo)
+		if s.isDefaultBranch(branch) {
+			branch = s.defaultBranch
+			if branch == "" {
+				branch = "HEAD"
+			}
+		}
+		if isPullRef(branch) {
+			return base + "/raw/" + gitRemoteRef(branch) + "/" + name, base + "/" + branch + "/files", nil
+		}
+		return base + "/raw/" + branch + "/" + name, base + "/blob/" + branch + "/" + name, nil
+	case "asset":
+		sl := strings.SplitN(u.Path, "/", 4)
+		if len(sl) != 4 {
+			return "", "", fmt.Errorf("%q not match asset://owner/repository/release/name", uri)
+		}
+		if isReleaseID(sl[2]) || sl[2] == prereleaseLatest {
+			return "", "", fmt.Errorf("%q needs the release tag to compute URLs", uri)
+		}
+		base := s.gitURL(u.Host, sl[1])
+		if sl[2] == releaseLatest {
+			return base + "/releases/latest/download/" + sl[3], base + "/releases/latest", nil
+		}
+		tag := s.releaseTagOf(sl[2])
+		return base + "/releases/download/" + tag + "/" + sl[3], base + "/releases/tag/" + tag, nil
+	case "gist":
+		sl := strings.SplitN(u.Path, "/", 3)
+		if len(sl) != 3 {
+			return "", "", fmt.Errorf("%q not match gist://owner/gist_id/name", uri)
+		}
+		if sl[1] == anyFile {
+			return "", "", fmt.Errorf("%q needs the gist id to compute URLs", uri)
+		}
+		page, content := s.gistHosts()
+		path := sl[1]
+		if u.Host != "" {
+			path = u.Host + "/" + path
+		}
+		return content + "/" + path + "/raw/" + sl[2], page + "/" + path, nil
+	}
+	return "", "", fmt.Errorf("%q not support", uri)
+}
+
+// gistHosts returns the base URLs of gist pages and of raw gist content.
+func (s *PutInGH) gistHosts() (page, content string) {
+	if s.host == "https://github.com" {
+		return "https://gist.github.com", "https://gist.githubusercontent.com"
+	}
+	return s.host + "/gist", s.host + "/gist"
+}
+
+// FromGistURL maps a gist page, raw or API URL such as https://gist.github.com/owner/id
+// or https://api.github.com/gists/id to gist://owner/id/name.
+// The name is taken from the URL when it carries one and name is empty,
+// as a path, a ?file= query or a #fragment.
+func (s *PutInGH) FromGistURL(gistURL, name string) (string, error) {
+	owner, id, file, err := s.parseGistURL(gistURL)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		file = name
+	}
+	if id == "" || file == "" {
+		return "", fmt.Errorf("%q needs a gist id and a file name", gistURL)
+	}
+	return "gist://" + owner + "/" + id + "/" + file, nil
+}
+
+// resolveGistURL is like FromGistURL, but looks the gist up when the URL names no file
+// or no owner, a gist with a single file then resolves to that file.
+func (s *PutInGH) resolveGistURL(ctx context.Context, gistURL string) (string, error) {
+	owner, id, file, err := s.resolveGist(ctx, gistURL)
+	if err != nil {
+		return "", err
+	}
+	return "gist://" + owner + "/" + id + "/" + file, nil
+}
+
+// resolveGist is resolveGistURL returning the owner, id and file of the gist instead of its URI.
+func (s *PutInGH) resolveGist(ctx context.Context, gistURL string) (owner, id, file string, err error) {
+	owner, id, file, err = s.parseGistURL(gistURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	if id == "" {
+		return "", "", "", fmt.Errorf("%q needs a gist id", gistURL)
+	}
+	if owner == "" || file == "" {
+		gist, response, err := s.cliv3.Gists.Get(ctx, id)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				return "", "", "", fmt.Errorf("%w: gist %s", ErrNotFound, id)
+			}
+			return "", "", "", err
+		}
+		if owner == "" {
+			owner = gist.GetOwner().GetLogin()
+		}
+		if file == "" {
+			if len(gist.Files) != 1 {
+				return "", "", "", fmt.Errorf("%q has %d files, name one with ?file= or #name", gistURL, len(gist.Files))
+			}
+			for filename := range gist.Files {
+				file = string(filename)
+			}
+		}
+	}
+	return owner, id, file, nil
+}
+
+// parseGistURL returns the parts of a gist URL, owner and file are empty when the URL does not carry them.
+func (s *PutInGH) parseGistURL(gistURL string) (owner, id, file string, err error) {
+	page, content := s.gistHosts()
+	api := s.apiURL
+	if api == "" {
+		api = "https://api.github.com/"
+	}
+	api = strings.TrimSuffix(api, "/") + "/gists"
+
+	u, err := url.Parse(gistURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	named := u.Query().Get("file")
+	if named == "" {
+		named = u.Fragment
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	link := strings.TrimSuffix(u.String(), "/")
+
+	switch {
+	case strings.HasPrefix(link, api+"/"):
+		sl := strings.SplitN(strings.TrimPrefix(link, api+"/"), "/", 2)
+		id = sl[0]
+		if len(sl) == 2 {
+			file = sl[1]
+		}
+	case strings.HasPrefix(link, page+"/"), strings.HasPrefix(link, content+"/"):
+		path := strings.TrimPrefix(strings.TrimPrefix(link, page+"/"), content+"/")
+		sl := strings.Split(path, "/")
+		if len(sl) < 2 {
+			return "", "", "", fmt.Errorf("%q not match %s/owner/gist_id", gistURL, page)
+		}
+		owner, id = sl[0], sl[1]
+		rest := sl[2:]
+		if len(rest) > 0 && rest[0] == "raw" {
+			// raw/<name> or raw/<revision>/<name>
+			rest = rest[1:]
+			if len(rest) > 0 {
+				rest = rest[len(rest)-1:]
+			}
+		}
+		file = strings.Join(rest, "/")
+	default:
+		return "", "", "", fmt.Errorf("%q is not a gist URL", gistURL)
+	}
+	if file == "" {
+		file = named
+	}
+	return owner, id, file, nil
+}
@@ -0,0 +1,124 @@
+package putingh_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ghv3 "github.com/google/go-github/v56/github"
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestFromGistURL(t *testing.T) {
+	putter := putingh.NewPutInGH("")
+	tests := []struct {
+		url  string
+		name string
+		want string
+	}{
+		{"https://gist.github.com/owner/abc123", "a.txt", "gist://owner/abc123/a.txt"},
+		{"https://gist.github.com/owner/abc123/raw/rev/a.txt", "", "gist://owner/abc123/a.txt"},
+		{"https://gist.githubusercontent.com/owner/abc123/raw/a.txt", "", "gist://owner/abc123/a.txt"},
+		{"https://gist.github.com/owner/abc123?file=a.txt", "", "gist://owner/abc123/a.txt"},
+		{"https://gist.github.com/owner/abc123#a.txt", "", "gist://owner/abc123/a.txt"},
+		{"https://api.github.com/gists/abc123/a.txt", "", "gist:///abc123/a.txt"},
+		{"https://api.github.com/gists/abc123", "a.txt", "gist:///abc123/a.txt"},
+	}
+	for _, tt := range tests {
+		got, err := putter.FromGistURL(tt.url, tt.name)
+		if err != nil {
+			t.Errorf("FromGistURL(%q, %q): %v", tt.url, tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FromGistURL(%q, %q) = %q, want %q", tt.url, tt.name, got, tt.want)
+		}
+	}
+	_, err := putter.FromGistURL("https://gist.github.com/owner/abc123", "")
+	if err == nil {
+		t.Error("a URL without a file name needs a name")
+	}
+}
+
+// gistID returns the id of the only gist of the putinghtest user.
+func gistID(t testing.TB, putter *putingh.PutInGH) string {
+	t.Helper()
+	gists, err := putter.FindGists(context.Background(), owner, func(*ghv3.Gist) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gists) != 1 {
+		t.Fatalf("got %d gists, want 1", len(gists))
+	}
+	return gists[0].GetID()
+}
+
+func TestGistURLWithoutName(t *testing.T) {
+	srv, putter := putinghtest.NewServer(t)
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/only.txt", strings.NewReader("only"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gistID(t, putter)
+
+	for _, u := range []string{
+		srv.URL + "/gist/" + owner + "/" + id,
+		srv.URL + "/api/v3/gists/" + id,
+	} {
+		got, err := putter.GetBytes(ctx, u)
+		if err != nil {
+			t.Fatalf("%s: %v", u, err)
+		}
+		if string(got) != "only" {
+			t.Fatalf("%s: got %q, want %q", u, got, "only")
+		}
+	}
+
+	_, err = putter.PutIn(ctx, srv.URL+"/api/v3/gists/"+id+"?file=second.txt", strings.NewReader("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := putter.GetBytes(ctx, srv.URL+"/gist/"+owner+"/"+id+"#second.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "second" {
+		t.Fatalf("got %q, want %q", got, "second")
+	}
+	_, err = putter.GetBytes(ctx, srv.URL+"/gist/"+owner+"/"+id)
+	if err == nil {
+		t.Fatal("a gist with two files needs a file name")
+	}
+}
+
+func TestGistURLLineEnding(t *testing.T) {
+	srv, _ := putinghtest.NewServer(t)
+	putter := newPutter(t, srv, putingh.WithLineEnding(lineEndingByExt), putingh.WithLineEndingOnRead(true))
+	ctx := context.Background()
+	_, err := putter.PutIn(ctx, "gist://"+owner+"/*/name.txt", strings.NewReader("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gistID(t, putter)
+
+	// the page URL does not end with the file name, the mode of the file is used
+	res, err := putter.PutInWithResult(ctx, srv.URL+"/api/v3/gists/"+id+"?file=name.txt", strings.NewReader("a\r\nb\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResult(t, res, []byte("a\nb\n"))
+	for _, uri := range []string{
+		"gist://" + owner + "/" + id + "/name.txt",
+		srv.URL + "/gist/" + owner + "/" + id + "#name.txt",
+	} {
+		got, err := putter.GetBytes(ctx, uri)
+		if err != nil {
+			t.Fatalf("%s: %v", uri, err)
+		}
+		if string(got) != "a\nb\n" {
+			t.Fatalf("%s: got %q, want %q", uri, got, "a\nb\n")
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	ghv3 "github.com/google/go-github/v56/github"
+)
+
+// Visibility is who can see a repository or a gist, a secret gist counts as private.
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"
+	VisibilityPrivate  Visibility = "private"
+	VisibilityInternal Visibility = "internal"
+)
+
+// WithVerifyVisibility makes every write fail with ErrVisibilityMismatch before anything is sent
+// when the repository or gist written to is not expected, such as a public one
+// for content meant to stay private. New gists are public.
+func WithVerifyVisibility(expected Visibility) Option {
+	return func(p *PutInGH) {
+		p.verifyVisibility = expected
+	}
+}
+
+// checkRepoVisibility looks up the visibility of the repository, it is not cached
+// so a repository made public in the meantime is caught.
+func (s *PutInGH) checkRepoVisibility(ctx context.Context, owner, repo string) error {
+	if s.verifyVisibility == "" {
+		return nil
+	}
+	repository, response, err := s.cliv3.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("repository %s/%s: %w", owner, repo, ErrNotFound)
+		}
+		return err
+	}
+	visibility := Visibility(repository.GetVisibility())
+	if visibility == "" {
+		visibility = VisibilityPublic
+		if repository.GetPrivate() {
+			visibility = VisibilityPrivate
+		}
+	}
+	if visibility != s.verifyVisibility {
+		return fmt.Errorf("%w: repository %s/%s is %s, expected %s", ErrVisibilityMismatch, owner, repo, visibility, s.verifyVisibility)
+	}
+	return nil
+}
+
+// checkGistVisibility checks the gist about to be written, nil for a gist about to be created.
+func (s *PutInGH) checkGistVisibility(gist *ghv3.Gist) error {
+	if s.verifyVisibility == "" {
+		return nil
+	}
+	visibility := VisibilityPublic
+	if gist != nil && !gist.GetPublic() {
+		visibility = VisibilityPrivate
+	}
+	if visibility != s.verifyVisibility {
+		id := "new gist"
+		if gist != nil {
+			id = "gist " + gist.GetID()
+		}
+		return fmt.Errorf("%w: %s is %s, expected %s", ErrVisibilityMismatch, id, visibility, s.verifyVisibility)
+	}
+	return nil
+}
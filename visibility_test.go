@@ -0,0 +1,57 @@
+package putingh_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wzshiming/putingh"
+	"github.com/wzshiming/putingh/putinghtest"
+)
+
+func TestVerifyVisibility(t *testing.T) {
+	for _, tc := range []struct {
+		uri      string
+		expected putingh.Visibility
+		mismatch bool
+	}{
+		{uri: "git://" + owner + "/repo/main/name.txt", expected: putingh.VisibilityPublic},
+		{uri: "git://" + owner + "/repo/main/name.txt", expected: putingh.VisibilityPrivate, mismatch: true},
+		{uri: "git://" + owner + "/" + putinghtest.PrivateRepoPrefix + "repo/main/name.txt", expected: putingh.VisibilityPrivate},
+		{uri: "git://" + owner + "/" + putinghtest.PrivateRepoPrefix + "repo/main/name.txt", expected: putingh.VisibilityPublic, mismatch: true},
+		{uri: "asset://" + owner + "/repo/v1/name.txt", expected: putingh.VisibilityPublic},
+		{uri: "asset://" + owner + "/repo/v1/name.txt", expected: putingh.VisibilityPrivate, mismatch: true},
+		{uri: "asset://" + owner + "/" + putinghtest.PrivateRepoPrefix + "repo/v1/name.txt", expected: putingh.VisibilityPrivate},
+		{uri: "gist://" + owner + "/*/name.txt", expected: putingh.VisibilityPublic},
+		{uri: "gist://" + owner + "/*/name.txt", expected: putingh.VisibilityPrivate, mismatch: true},
+	} {
+		t.Run(string(tc.expected)+" "+tc.uri, func(t *testing.T) {
+			srv, _ := putinghtest.NewServer(t)
+			var writes atomic.Int32
+			handler := srv.Config.Handler
+			srv.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet && !strings.HasSuffix(r.URL.Path, "/git-upload-pack") {
+					writes.Add(1)
+				}
+				handler.ServeHTTP(rw, r)
+			})
+			putter := newPutter(t, srv, putingh.WithVerifyVisibility(tc.expected))
+			_, err := putter.PutIn(context.Background(), tc.uri, strings.NewReader("content"))
+			if !tc.mismatch {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, putingh.ErrVisibilityMismatch) {
+				t.Fatalf("got %v, want ErrVisibilityMismatch", err)
+			}
+			if n := writes.Load(); n != 0 {
+				t.Fatalf("sent %d writes before failing", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package putingh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WhoAmI returns the login of the authenticated user, cached after the first successful call.
+func (s *PutInGH) WhoAmI(ctx context.Context) (string, error) {
+	s.loginMut.Lock()
+	defer s.loginMut.Unlock()
+	if s.login != "" {
+		return s.login, nil
+	}
+	user, response, err := s.cliv3.Users.Get(ctx, "")
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusUnauthorized {
+			return "", fmt.Errorf("%w: token rejected: %v", ErrUnauthorized, err)
+		}
+		return "", err
+	}
+	login := user.GetLogin()
+	if login == "" {
+		return "", fmt.Errorf("%w: no login for the token", ErrUnauthorized)
+	}
+	s.login = login
+	return login, nil
+}
+
+// impliedScopes lists the scopes granted by a broader scope.
+var impliedScopes = map[string][]string{
+	"repo":           {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+	"admin:org":      {"write:org", "read:org"},
+	"write:org":      {"read:org"},
+	"user":           {"read:user", "user:email", "user:follow"},
+	"write:packages": {"read:packages"},
+}
+
+// CheckScopes returns ErrMissingScopes listing the required scopes the token lacks,
+// tokens without classic scopes such as fine-grained tokens can not be checked and pass.
+func (s *PutInGH) CheckScopes(ctx context.Context, required ...string) error {
+	_, response, err := s.cliv3.Users.Get(ctx, "")
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("%w: token rejected: %v", ErrUnauthorized, err)
+		}
+		return err
+	}
+	header, ok := response.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !ok {
+		return nil
+	}
+	granted := map[string]bool{}
+	for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		granted[scope] = true
+		for _, implied := range impliedScopes[scope] {
+			granted[implied] = true
+		}
+	}
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: %s", ErrMissingScopes, strings.Join(missing, ", "))
+	}
+	return nil
+}